@@ -56,3 +56,17 @@ func renderTextOnBorder(text string, edge renderEdge, side offSetSide, offset in
 	miniView := tcellviews.NewViewPort(view, x, y, numCells, 1)
 	TcellDrawHelper(text, miniView, []*tcellviews.ViewPort{})
 }
+
+// viewContains checks if the point x, y given in the coordinates
+// of the parent of view lies inside view. It also returns the point
+// relative to view
+func viewContains(view *tcellviews.ViewPort, x int, y int) (int, int, bool) {
+	if view == nil {
+		return 0, 0, false
+	}
+	px, py, pX, pY := view.GetPhysical()
+	if x < px || x > pX || y < py || y > pY {
+		return 0, 0, false
+	}
+	return x - px, y - py, true
+}
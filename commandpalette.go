@@ -0,0 +1,316 @@
+package peanutbutter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+)
+
+// CommandEntry is a key binding that can be run from the command palette
+type CommandEntry struct {
+	Binding *KeyBinding
+	Owner   IPanel // the panel the binding belongs to, nil for global key layers
+}
+
+func (e CommandEntry) helpText() string {
+	if e.Binding.ShortHelp != "" {
+		return e.Binding.ShortHelp
+	}
+	return e.Binding.LongHelp
+}
+
+// reachableKeyBindings returns the enabled bindings with a Func that
+// a key-stroke can currently reach: those of the global key layers,
+// those on the focus path (the most deeply nested panel first) and
+// the global bindings of all panels
+func (m *TopLevelListPanel) reachableKeyBindings(ctx *KeyContext) []CommandEntry {
+	entries := []CommandEntry{}
+	seen := make(map[*KeyBinding]bool)
+	add := func(owner IPanel, keyBindings []*KeyBinding, onlyGlobal bool) {
+		for _, keyBinding := range keyBindings {
+			if seen[keyBinding] || (onlyGlobal && !keyBinding.Global) {
+				continue
+			}
+			if !keyBinding.Enabled || keyBinding.Func == nil || !keyBinding.IsActive(ctx) {
+				continue
+			}
+			seen[keyBinding] = true
+			entries = append(entries, CommandEntry{Binding: keyBinding, Owner: owner})
+		}
+	}
+
+	add(nil, m.globalKeyLayers.ActiveKeyBindings(nil), false)
+	focusPath := FocusPath(m.ListPanel)
+	for i := len(focusPath) - 1; i >= 0; i-- {
+		add(focusPath[i], activeKeyBindings(focusPath[i]), false)
+	}
+	for _, panel := range m.GlobalShortcutOrder() {
+		add(panel, activeKeyBindings(panel), true)
+	}
+	return entries
+}
+
+// CommandEntries returns the reachable bindings that have help text
+// Bindings without keys are included, so actions can be exposed
+// through the command palette only
+func (m *TopLevelListPanel) CommandEntries() []CommandEntry {
+	ctx := m.KeyContext()
+	entries := []CommandEntry{}
+	for _, entry := range m.reachableKeyBindings(&ctx) {
+		if entry.helpText() != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// RunCommand runs the binding of the entry as if its key had been
+// pressed, routing the resulting command the way the owning panel would
+func (m *TopLevelListPanel) RunCommand(entry CommandEntry) {
+	cmd := entry.Binding.Func()
+	if _, ok := entry.Owner.(*ShortCutPanel); ok {
+		cmd = MakeAutoRoutedCmd(cmd, entry.Owner.GetPath())
+	}
+	m.cmds <- cmd
+}
+
+// fuzzyScore matches the characters of query in order against text,
+// ignoring case. Matches at the start of a word and runs of
+// consecutive matches score higher
+func fuzzyScore(query string, text string) (int, bool) {
+	q := []rune(strings.ToLower(query))
+	t := []rune(strings.ToLower(text))
+	if len(q) == 0 {
+		return 0, true
+	}
+	score := 0
+	qi := 0
+	lastMatch := -2
+	for ti := 0; ti < len(t) && qi < len(q); ti++ {
+		if t[ti] != q[qi] {
+			continue
+		}
+		score++
+		if ti == lastMatch+1 {
+			score += 3
+		}
+		if ti == 0 || !unicode.IsLetter(t[ti-1]) && !unicode.IsDigit(t[ti-1]) {
+			score += 5
+		}
+		lastMatch = ti
+		qi++
+	}
+	if qi < len(q) {
+		return 0, false
+	}
+	return score, true
+}
+
+// CommandPalette is a leaf model that lists the entries given by
+// TopLevelListPanel.CommandEntries, fuzzy filtered by their help
+// text, and runs the chosen one
+type CommandPalette struct {
+	topLevel *TopLevelListPanel
+	panel    IPanel
+	entries  []CommandEntry
+	filtered []CommandEntry
+	query    string
+	selected int
+	offset   int
+	width    int
+	height   int
+	redraw   bool
+	Style    CommandPaletteStyle
+}
+
+type CommandPaletteStyle struct {
+	Prompt   lipgloss.Style
+	Entry    lipgloss.Style
+	Selected lipgloss.Style
+	Keys     lipgloss.Style
+}
+
+var DefaultCommandPaletteStyle = CommandPaletteStyle{
+	Prompt:   lipgloss.NewStyle().Bold(true),
+	Entry:    lipgloss.NewStyle(),
+	Selected: lipgloss.NewStyle().Reverse(true),
+	Keys:     Fg(Plt.Overlay1()),
+}
+
+var _ ILeafModel = &CommandPalette{}
+var _ ILeafModelWithView = &CommandPalette{}
+
+func NewCommandPalette(topLevel *TopLevelListPanel, entries []CommandEntry) *CommandPalette {
+	palette := &CommandPalette{
+		topLevel: topLevel,
+		entries:  entries,
+		redraw:   true,
+		Style:    DefaultCommandPaletteStyle,
+	}
+	palette.filter()
+	return palette
+}
+
+// ShowCommandPalette opens the command palette as a modal overlay
+func (m *TopLevelListPanel) ShowCommandPalette() {
+	palette := NewCommandPalette(m, m.CommandEntries())
+	palette.panel = NewShortCutPanel(palette, WithTitle("Commands"), WithName("Commands"))
+	m.ShowOverlay(palette.panel, CenteredOverlay(0.6, 0.6), true)
+}
+
+// WithCommandPaletteKeyBinding adds a global key binding
+// that opens the command palette
+func WithCommandPaletteKeyBinding(keyBinding KeyBinding) TopLevelListPanelOption {
+	newKb := keyBinding
+	return func(m *TopLevelListPanel) {
+		newKb.Global = true
+		newKb.Func = func() tea.Cmd {
+			m.ShowCommandPalette()
+			return nil
+		}
+		m.ListPanel.AddKeyBinding(&newKb)
+	}
+}
+
+func (c *CommandPalette) Init() tea.Cmd {
+	return nil
+}
+
+func (c *CommandPalette) filter() {
+	type scored struct {
+		entry CommandEntry
+		score int
+	}
+	matches := []scored{}
+	for _, entry := range c.entries {
+		text := entry.Binding.ShortHelp + " " + entry.Binding.LongHelp
+		if score, ok := fuzzyScore(c.query, text); ok {
+			matches = append(matches, scored{entry: entry, score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+	c.filtered = make([]CommandEntry, len(matches))
+	for i, match := range matches {
+		c.filtered[i] = match.entry
+	}
+	c.selected = 0
+	c.offset = 0
+	c.redraw = true
+}
+
+func (c *CommandPalette) moveSelection(delta int) {
+	if len(c.filtered) == 0 {
+		return
+	}
+	c.selected = (c.selected + delta + len(c.filtered)) % len(c.filtered)
+	c.redraw = true
+}
+
+func (c *CommandPalette) close() {
+	if c.topLevel != nil && c.panel != nil {
+		c.topLevel.HideOverlay(c.panel)
+	}
+}
+
+func (c *CommandPalette) run() {
+	if c.selected >= len(c.filtered) {
+		return
+	}
+	entry := c.filtered[c.selected]
+	c.close()
+	if c.topLevel != nil {
+		c.topLevel.RunCommand(entry)
+	}
+}
+
+func (c *CommandPalette) handleKey(msg KeyMsg) {
+	switch msg.Key() {
+	case tcell.KeyEsc:
+		c.close()
+	case tcell.KeyEnter:
+		c.run()
+	case tcell.KeyUp, tcell.KeyCtrlP:
+		c.moveSelection(-1)
+	case tcell.KeyDown, tcell.KeyCtrlN:
+		c.moveSelection(1)
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if c.query != "" {
+			runes := []rune(c.query)
+			c.query = string(runes[:len(runes)-1])
+			c.filter()
+		}
+	case tcell.KeyRune:
+		c.query += string(msg.Rune())
+		c.filter()
+	}
+}
+
+func (c *CommandPalette) Update(msg Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case ResizeMsg:
+		c.width = msg.Width
+		c.height = msg.Height
+		c.redraw = true
+	case KeyMsg:
+		c.handleKey(msg)
+	case PasteMsg:
+		c.query += strings.ReplaceAll(msg.Text, "\n", " ")
+		c.filter()
+	case FocusGrantMsg, FocusRevokeMsg:
+		c.redraw = true
+	}
+	return nil
+}
+
+func (c *CommandPalette) NeedsRedraw() bool {
+	return c.redraw
+}
+
+// padLine truncates or pads s with spaces to exactly width cells
+func padLine(s string, width int) string {
+	s = runewidth.Truncate(s, width, "…")
+	return s + strings.Repeat(" ", width-runewidth.StringWidth(s))
+}
+
+func (c *CommandPalette) View() string {
+	c.redraw = false
+	if c.width <= 0 || c.height <= 0 {
+		return ""
+	}
+	lines := []string{c.Style.Prompt.Render(padLine("> "+c.query, c.width))}
+
+	visible := c.height - 1
+	if c.selected < c.offset {
+		c.offset = c.selected
+	}
+	if c.selected >= c.offset+visible {
+		c.offset = c.selected - visible + 1
+	}
+	for i := c.offset; i < len(c.filtered) && len(lines) < c.height; i++ {
+		entry := c.filtered[i]
+		keys := entry.Binding.renderKeys()
+		helpWidth := c.width - runewidth.StringWidth(keys) - 1
+		if helpWidth < 1 {
+			helpWidth = c.width
+			keys = ""
+		}
+		help := padLine(entry.helpText(), helpWidth)
+		if i == c.selected {
+			lines = append(lines, c.Style.Selected.Render(padLine(fmt.Sprintf("%s %s", help, keys), c.width)))
+		} else {
+			lines = append(lines, c.Style.Entry.Render(help)+" "+c.Style.Keys.Render(keys))
+		}
+	}
+	for len(lines) < c.height {
+		lines = append(lines, strings.Repeat(" ", c.width))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,93 @@
+package peanutbutter
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gdamore/tcell/v2"
+)
+
+func newPaletteLayout(t *testing.T) *testLayout {
+	t.Helper()
+	return newTestLayout(t, WithCommandPaletteKeyBinding(*NewKeyBinding(WithKeys("Ctrl+K"), WithEnabled(true))))
+}
+
+// openPalette opens the command palette and returns it
+func (l *testLayout) openPalette(t *testing.T) *CommandPalette {
+	t.Helper()
+	l.press(tcell.KeyCtrlK, 0, tcell.ModCtrl)
+	if len(l.top.overlays) != 1 {
+		t.Fatalf("%d overlays shown, want 1", len(l.top.overlays))
+	}
+	return l.top.overlays[0].panel.(*ShortCutPanel).Model.(*CommandPalette)
+}
+
+func TestCommandPaletteRunsFilteredCommand(t *testing.T) {
+	l := newPaletteLayout(t)
+	ran := ""
+	l.b.AddKeyBinding(testKeyBinding("x", func() tea.Cmd {
+		ran += "x"
+		return nil
+	}, WithGlobal(true), WithShortHelp("Do thing")))
+	l.b.AddKeyBinding(testKeyBinding("y", func() tea.Cmd {
+		ran += "y"
+		return nil
+	}, WithGlobal(true), WithShortHelp("Other")))
+
+	palette := l.openPalette(t)
+	l.typeRunes("dth")
+	if len(palette.filtered) != 1 || palette.filtered[0].Binding.ShortHelp != "Do thing" {
+		t.Fatalf("filtered %d entries for %q", len(palette.filtered), palette.query)
+	}
+	l.press(tcell.KeyEnter, 0, 0)
+	if ran != "x" {
+		t.Errorf("ran %q, want %q", ran, "x")
+	}
+	if len(l.top.overlays) != 0 {
+		t.Error("palette still shown after running a command")
+	}
+}
+
+func TestCommandPaletteEscCloses(t *testing.T) {
+	l := newPaletteLayout(t)
+	l.openPalette(t)
+	l.press(tcell.KeyEsc, 0, 0)
+	if len(l.top.overlays) != 0 {
+		t.Error("palette still shown after Esc")
+	}
+}
+
+func TestCommandPaletteTakesPaste(t *testing.T) {
+	l := newPaletteLayout(t)
+	l.b.AddKeyBinding(testKeyBinding("x", func() tea.Cmd { return nil }, WithGlobal(true), WithShortHelp("Do thing")))
+	l.focus(l.a)
+	palette := l.openPalette(t)
+
+	l.top.HandleMessage(PasteMsg{Text: "do\nth"})
+	l.pump()
+	if palette.query != "do th" {
+		t.Errorf("query is %q, want %q", palette.query, "do th")
+	}
+	for _, msg := range l.leaf(l.a).msgs {
+		if _, ok := msg.(PasteMsg); ok {
+			t.Error("focused a got the paste behind the palette")
+		}
+	}
+}
+
+func TestFuzzyScore(t *testing.T) {
+	if _, ok := fuzzyScore("dth", "Do thing"); !ok {
+		t.Error("dth does not match Do thing")
+	}
+	if _, ok := fuzzyScore("htd", "Do thing"); ok {
+		t.Error("htd matches Do thing out of order")
+	}
+	if _, ok := fuzzyScore("", "anything"); !ok {
+		t.Error("empty query does not match")
+	}
+	wordStart, _ := fuzzyScore("t", "Do thing")
+	inWord, _ := fuzzyScore("t", "Dot")
+	if wordStart <= inWord {
+		t.Errorf("word start scored %d, not above %d", wordStart, inWord)
+	}
+}
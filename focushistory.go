@@ -0,0 +1,128 @@
+package peanutbutter
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// defaultFocusHistorySize is the number of panels remembered
+// by the focus history unless WithFocusHistorySize says otherwise
+const defaultFocusHistorySize = 100
+
+// To go back and forth in the focus history, send a FocusRequestMsg
+// with the Relation FocusBack or FocusForward
+
+// focusHistory is the trail of panels that received the focus,
+// oldest first. index is the entry of the focused panel; entries
+// after it were left by going back
+type focusHistory struct {
+	entries []IPanel
+	index   int
+	size    int
+}
+
+// WithFocusHistorySize sets how many panels the focus history remembers
+func WithFocusHistorySize(size int) TopLevelListPanelOption {
+	return func(m *TopLevelListPanel) {
+		m.focusHistory.size = size
+	}
+}
+
+// WithFocusHistoryKeyBindings adds global key bindings that go back
+// and forward in the focus history, e.g. Ctrl+O and Ctrl+P. Terminals
+// report Ctrl+I as Tab, so it clashes with WithTabOrder
+func WithFocusHistoryKeyBindings(back KeyBinding, forward KeyBinding) TopLevelListPanelOption {
+	backKb, forwardKb := back, forward
+	return func(m *TopLevelListPanel) {
+		for _, kb := range []*KeyBinding{&backKb, &forwardKb} {
+			relation := FocusBack
+			if kb == &forwardKb {
+				relation = FocusForward
+			}
+			kb.Global = true
+			kb.Func = func() tea.Cmd {
+				return func() tea.Msg {
+					return FocusRequestMsg{Relation: relation}
+				}
+			}
+			m.ListPanel.AddKeyBinding(kb)
+		}
+	}
+}
+
+// FocusHistory returns the panels in the focus history, oldest first,
+// and the index of the current one. Panels that were removed from the
+// layout, hidden or disabled are pruned first
+func (m *TopLevelListPanel) FocusHistory() ([]IPanel, int) {
+	m.pruneFocusHistory()
+	return append([]IPanel{}, m.focusHistory.entries...), m.focusHistory.index
+}
+
+// isInFocusHistoryReach returns true if panel is still part of the
+// layout and can take the focus
+func (m *TopLevelListPanel) isInFocusHistoryReach(panel IPanel) bool {
+	return FindPanelByPath(m.ListPanel, panel.GetPath()) == panel && IsPanelFocusable(panel)
+}
+
+// pruneFocusHistory drops the panels that can no longer be
+// focused, along with repeats left behind by dropping them
+func (m *TopLevelListPanel) pruneFocusHistory() {
+	h := &m.focusHistory
+	entries := []IPanel{}
+	index := 0
+	for i, panel := range h.entries {
+		if m.isInFocusHistoryReach(panel) && (len(entries) == 0 || entries[len(entries)-1] != panel) {
+			entries = append(entries, panel)
+		}
+		if i == h.index {
+			index = max(len(entries)-1, 0)
+		}
+	}
+	h.entries = entries
+	h.index = index
+}
+
+// recordFocus adds the most deeply nested focused panel to the focus
+// history after a focus grant. Any move other than going back or
+// forward drops the entries that were gone back over
+func (m *TopLevelListPanel) recordFocus(relation Relation) {
+	focusPath := FocusPath(m.ListPanel)
+	if len(focusPath) == 0 {
+		return
+	}
+	focused := focusPath[len(focusPath)-1]
+	h := &m.focusHistory
+	if relation == FocusBack || relation == FocusForward {
+		return // moveInFocusHistory already moved the index
+	}
+	if len(h.entries) > 0 && h.entries[h.index] == focused {
+		return
+	}
+	if len(h.entries) > 0 {
+		h.entries = h.entries[:h.index+1]
+	}
+	h.entries = append(h.entries, focused)
+	size := h.size
+	if size <= 0 {
+		size = defaultFocusHistorySize
+	}
+	if len(h.entries) > size {
+		h.entries = h.entries[len(h.entries)-size:]
+	}
+	h.index = len(h.entries) - 1
+}
+
+// moveInFocusHistory returns the focus grant for the previous or
+// next panel in the focus history, or nil at either end
+func (m *TopLevelListPanel) moveInFocusHistory(relation Relation) *FocusGrantMsg {
+	m.pruneFocusHistory()
+	h := &m.focusHistory
+	target := h.index - 1
+	if relation == FocusForward {
+		target = h.index + 1
+	}
+	if target < 0 || target >= len(h.entries) {
+		return nil
+	}
+	h.index = target
+	return &FocusGrantMsg{RoutePath: RoutePath{Path: h.entries[target].GetPath()}, Relation: relation}
+}
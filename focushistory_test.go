@@ -0,0 +1,97 @@
+package peanutbutter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func newFocusHistoryLayout(t *testing.T, options ...TopLevelListPanelOption) *testLayout {
+	t.Helper()
+	options = append(options, WithFocusHistoryKeyBindings(
+		*NewKeyBinding(WithKeys("Ctrl+O"), WithEnabled(true)),
+		*NewKeyBinding(WithKeys("Ctrl+P"), WithEnabled(true)),
+	))
+	return newTestLayout(t, options...)
+}
+
+func (l *testLayout) back()    { l.press(tcell.KeyCtrlO, 0, tcell.ModCtrl) }
+func (l *testLayout) forward() { l.press(tcell.KeyCtrlP, 0, tcell.ModCtrl) }
+
+// focusHistory renders the history with the current entry in brackets
+func (l *testLayout) focusHistory() string {
+	entries, index := l.top.FocusHistory()
+	names := []string{}
+	for i, panel := range entries {
+		name := panel.GetName()
+		if i == index {
+			name = "[" + name + "]"
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, " ")
+}
+
+func TestFocusHistoryBackAndForward(t *testing.T) {
+	l := newFocusHistoryLayout(t)
+	l.focus(l.a)
+	l.focus(l.b)
+	l.focus(l.c)
+	l.back()
+	l.back()
+	if l.focused() != l.a || l.focusHistory() != "[a] b c" {
+		t.Fatalf("focus on %v, history %q", panelName(l.focused()), l.focusHistory())
+	}
+	l.back()
+	if l.focused() != l.a {
+		t.Errorf("focus on %v after going back past the start", panelName(l.focused()))
+	}
+	l.forward()
+	if l.focused() != l.b || l.focusHistory() != "a [b] c" {
+		t.Errorf("focus on %v, history %q", panelName(l.focused()), l.focusHistory())
+	}
+
+	// a new focus drops the entries that were gone back over
+	l.focus(l.a)
+	if history := l.focusHistory(); history != "a b [a]" {
+		t.Errorf("history %q", history)
+	}
+	l.forward()
+	if l.focused() != l.a {
+		t.Errorf("focus on %v after going forward past the end", panelName(l.focused()))
+	}
+}
+
+func TestFocusHistorySkipsDisabledPanels(t *testing.T) {
+	l := newFocusHistoryLayout(t)
+	l.focus(l.a)
+	l.focus(l.b)
+	l.focus(l.c)
+	l.b.SetEnabled(false)
+	l.back()
+	if l.focused() != l.a || l.focusHistory() != "[a] c" {
+		t.Errorf("focus on %v, history %q", panelName(l.focused()), l.focusHistory())
+	}
+}
+
+func TestFocusHistoryDropsRemovedTab(t *testing.T) {
+	l := newFocusHistoryLayout(t)
+	l.focus(l.c)
+	l.focus(l.a)
+	l.runTabCmd(l.tabs.RemoveTab(0))
+	l.back()
+	if l.focused() != l.a || l.focusHistory() != "[a]" {
+		t.Errorf("focus on %v, history %q", panelName(l.focused()), l.focusHistory())
+	}
+}
+
+func TestFocusHistorySize(t *testing.T) {
+	l := newFocusHistoryLayout(t, WithFocusHistorySize(2))
+	l.focus(l.a)
+	l.focus(l.b)
+	l.focus(l.c)
+	if history := l.focusHistory(); history != "b [c]" {
+		t.Errorf("history %q", history)
+	}
+}
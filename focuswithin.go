@@ -0,0 +1,76 @@
+package peanutbutter
+
+import "github.com/charmbracelet/lipgloss"
+
+// focusState is how a ListPanel shows the focus
+type focusState int
+
+const (
+	unfocused focusState = iota
+	focusWithin
+	focusedDirectly
+)
+
+// WithFocusWithin makes a ListPanel draw its FocusedBorder while any
+// of its descendants is focused, with its title or selected tab in
+// the FocusWithinTitle style to tell it apart from direct focus
+func WithFocusWithin(enabled bool) ListPanelOption {
+	return func(m *ListPanel) {
+		m.focusWithin = enabled
+	}
+}
+
+// WithListPanelTitle sets a title drawn on the top border of a
+// Horizontal or Vertical ListPanel. ZStacked ListPanels show
+// their tab bar instead
+func WithListPanelTitle(title string) ListPanelOption {
+	return func(m *ListPanel) {
+		m.title = title
+	}
+}
+
+// IsFocusWithin returns true if a descendant of the ListPanel
+// is focused, rather than the ListPanel itself
+func (p *ListPanel) IsFocusWithin() bool {
+	return !p.iAmInFocus && p.IsFocused()
+}
+
+func (p *ListPanel) focusState() focusState {
+	switch {
+	case p.iAmInFocus:
+		return focusedDirectly
+	case p.focusWithin && p.IsFocusWithin():
+		return focusWithin
+	default:
+		return unfocused
+	}
+}
+
+func (p *ListPanel) renderTitle() {
+	if p.title == "" {
+		return
+	}
+	titleStyle := p.titleStyle.UnfocusedTitle
+	switch p.focusState() {
+	case focusedDirectly:
+		titleStyle = p.titleStyle.FocusedTitle
+	case focusWithin:
+		titleStyle = p.titleStyle.focusWithinTitle()
+	}
+	renderTextOnBorder(
+		titleStyle.Render(p.title),
+		renderOnTopEdge,
+		offsetFromLeftSide,
+		titleOffset,
+		p.view,
+	)
+}
+
+// focusWithinTitle returns FocusWithinTitle, or FocusedTitle
+// for title styles that leave it unset
+func (t *TitleStyle) focusWithinTitle() lipgloss.Style {
+	if t.FocusWithinTitle == nil {
+		return t.FocusedTitle
+	}
+	return *t.FocusWithinTitle
+}
@@ -0,0 +1,58 @@
+package peanutbutter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestFocusWithinState(t *testing.T) {
+	l := buildTestLayout()
+	WithFocusWithin(true)(l.right)
+	WithFocusWithin(true)(l.tabs)
+	l.start(t)
+
+	l.focus(l.c)
+	if !l.tabs.IsFocusWithin() || l.tabs.focusState() != focusWithin || l.right.focusState() != focusWithin {
+		t.Errorf("tabs are in state %v, right in %v with c focused", l.tabs.focusState(), l.right.focusState())
+	}
+	// only ZStacked lists take the focus themselves
+	l.focus(l.tabs)
+	if l.tabs.IsFocusWithin() || l.tabs.focusState() != focusedDirectly || l.right.focusState() != focusWithin {
+		t.Errorf("tabs are in state %v, right in %v with tabs focused", l.tabs.focusState(), l.right.focusState())
+	}
+	l.focus(l.a)
+	if l.tabs.focusState() != unfocused || l.right.focusState() != unfocused {
+		t.Errorf("tabs are in state %v, right in %v with a focused", l.tabs.focusState(), l.right.focusState())
+	}
+
+	// without the option, focus within only shows in IsFocusWithin
+	if !l.top.IsFocusWithin() || l.top.focusState() != unfocused {
+		t.Errorf("top level is in state %v with a focused", l.top.focusState())
+	}
+}
+
+func TestListPanelTitle(t *testing.T) {
+	l := buildTestLayout()
+	WithFocusWithin(true)(l.right)
+	WithListPanelTitle("right")(l.right)
+	l.start(t)
+	l.focus(l.b)
+	l.draw()
+	if row := l.row(0); !strings.Contains(row, "right") {
+		t.Errorf("title missing from %q", row)
+	}
+}
+
+func TestFocusWithinTitleFallback(t *testing.T) {
+	titleStyle := TitleStyle{FocusedTitle: lipgloss.NewStyle().Bold(true)}
+	if style := titleStyle.focusWithinTitle(); !style.GetBold() {
+		t.Error("unset FocusWithinTitle does not fall back to FocusedTitle")
+	}
+	italic := lipgloss.NewStyle().Italic(true)
+	titleStyle.FocusWithinTitle = &italic
+	if style := titleStyle.focusWithinTitle(); style.GetBold() || !style.GetItalic() {
+		t.Error("FocusWithinTitle not used once set")
+	}
+}
@@ -0,0 +1,79 @@
+package peanutbutter
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gdamore/tcell/v2"
+)
+
+var ctrlL = KeyDef{Key: tcell.KeyCtrlL, Modifiers: tcell.ModCtrl}
+
+func TestGlobalShortcutWorksWithoutFocus(t *testing.T) {
+	l := newTestLayout(t)
+	fired := 0
+	l.b.AddKeyBinding(testKeyDefBinding(ctrlL, func() tea.Cmd {
+		fired++
+		return nil
+	}, WithGlobal(true)))
+
+	l.press(tcell.KeyCtrlL, 0, tcell.ModCtrl)
+	l.focus(l.a)
+	l.press(tcell.KeyCtrlL, 0, tcell.ModCtrl)
+	if fired != 2 {
+		t.Fatalf("fired %d times, want 2", fired)
+	}
+	if keyMsgs := l.leaf(l.a).keyMsgs(); len(keyMsgs) != 0 {
+		t.Errorf("focused a also got the key")
+	}
+}
+
+func TestGlobalShortcutOrder(t *testing.T) {
+	l := newTestLayout(t)
+	fired := ""
+	for _, panel := range []*ShortCutPanel{l.a, l.b, l.d} {
+		name := panel.GetName()
+		panel.AddKeyBinding(testKeyDefBinding(ctrlL, func() tea.Cmd {
+			fired += name
+			return nil
+		}, WithGlobal(true)))
+	}
+
+	// the focus path goes first
+	l.focus(l.b)
+	l.press(tcell.KeyCtrlL, 0, tcell.ModCtrl)
+	// then visible panels in path order, hidden tabs last
+	l.focus(l.c)
+	l.press(tcell.KeyCtrlL, 0, tcell.ModCtrl)
+	if fired != "ba" {
+		t.Errorf("fired %q, want %q", fired, "ba")
+	}
+
+	order := l.top.GlobalShortcutOrder()
+	if order[0] != l.c || order[len(order)-1] != l.d {
+		t.Errorf("order starts with %v and ends with %v", order[0].GetName(), order[len(order)-1].GetName())
+	}
+}
+
+func TestNonGlobalBindingNeedsFocus(t *testing.T) {
+	l := newTestLayout(t)
+	fired := false
+	l.b.AddKeyBinding(testKeyDefBinding(ctrlL, func() tea.Cmd {
+		fired = true
+		return nil
+	}))
+	l.focus(l.a)
+	l.press(tcell.KeyCtrlL, 0, tcell.ModCtrl)
+	if fired {
+		t.Error("binding of unfocused b fired")
+	}
+}
+
+func TestUnboundGlobalShortcutQueuesNoCommand(t *testing.T) {
+	l := newTestLayout(t)
+	keyMsg := testKeyMsg(tcell.KeyRune, 'z', 0)
+	l.right.HandleMessage(ConsiderForGlobalShortcutMsg{KeyMsg: keyMsg, RoutePath: RoutePath{Path: l.right.GetPath()}})
+	if len(l.cmds) != 0 {
+		t.Fatalf("queued %d commands for a key without a global binding", len(l.cmds))
+	}
+}
@@ -0,0 +1,229 @@
+package peanutbutter
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gdamore/tcell/v2"
+	tcellviews "github.com/gdamore/tcell/v2/views"
+)
+
+// testLeaf records the messages it receives. With passKeys set it
+// leaves key-strokes unused, so that the bindings of its parents
+// still get to see them
+type testLeaf struct {
+	msgs     []Msg
+	passKeys bool
+}
+
+func (l *testLeaf) Update(msg Msg) tea.Cmd {
+	l.msgs = append(l.msgs, msg)
+	if keyMsg, ok := msg.(KeyMsg); ok && l.passKeys {
+		keyMsg.SetUnused()
+	}
+	return nil
+}
+
+func (l *testLeaf) Init() tea.Cmd     { return nil }
+func (l *testLeaf) NeedsRedraw() bool { return true }
+func (l *testLeaf) View() string      { return "" }
+
+func (l *testLeaf) keyMsgs() []KeyMsg {
+	keyMsgs := []KeyMsg{}
+	for _, msg := range l.msgs {
+		if keyMsg, ok := msg.(KeyMsg); ok {
+			keyMsgs = append(keyMsgs, keyMsg)
+		}
+	}
+	return keyMsgs
+}
+
+// testLayout is an 80x24 screen split into a on the left and, on the
+// right, b above the ZStacked list tabs holding c and d:
+//
+//	+-----+-----+
+//	|     |  b  |
+//	|  a  +-----+
+//	|     | c,d |
+//	+-----+-----+
+type testLayout struct {
+	screen     tcell.SimulationScreen
+	top        *TopLevelListPanel
+	right      *ListPanel
+	tabs       *ListPanel
+	a, b, c, d *ShortCutPanel
+	cmds       chan tea.Cmd
+}
+
+func newTestPanel(name string) *ShortCutPanel {
+	return NewShortCutPanel(&testLeaf{}, WithName(name), WithTitle(name))
+}
+
+func newTestLayout(t *testing.T, options ...TopLevelListPanelOption) *testLayout {
+	t.Helper()
+	l := buildTestLayout(options...)
+	l.start(t)
+	return l
+}
+
+// buildTestLayout makes the layout without starting it, so that
+// options can still be given to its ListPanels
+func buildTestLayout(options ...TopLevelListPanelOption) *testLayout {
+	l := &testLayout{
+		a: newTestPanel("a"),
+		b: newTestPanel("b"),
+		c: newTestPanel("c"),
+		d: newTestPanel("d"),
+	}
+	l.tabs = NewListPanel([]IPanel{l.c, l.d}, Layout{Orientation: ZStacked}, WithListPanelName("tabs"))
+	l.right = NewListPanel([]IPanel{l.b, l.tabs}, Layout{Orientation: Vertical, Dimensions: []Dimension{{Ratio: 0.5}, {}}})
+	root := NewListPanel([]IPanel{l.a, l.right}, Layout{Orientation: Horizontal, Dimensions: []Dimension{{Ratio: 0.5}, {}}})
+	l.top = NewTopLevelListPanel(root, options...)
+	return l
+}
+
+// start initializes and sizes the top level panel on a simulation screen
+func (l *testLayout) start(t *testing.T) {
+	t.Helper()
+	l.screen = tcell.NewSimulationScreen("")
+	if err := l.screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	l.screen.SetSize(80, 24)
+	l.cmds = make(chan tea.Cmd, 1000)
+	l.top.SetView(tcellviews.NewViewPort(l.screen, 0, 0, -1, -1))
+	l.top.Init(l.cmds)
+	l.top.HandleMessage(ResizeMsg{Width: 80, Height: 24})
+	l.pump()
+}
+
+// run runs the pending commands and returns the messages they
+// produce, without handing them to the top level panel
+func (l *testLayout) run() []tea.Msg {
+	msgs := []tea.Msg{}
+	for {
+		select {
+		case cmd := <-l.cmds:
+			if cmd == nil {
+				continue
+			}
+			msg := cmd()
+			if batch, ok := msg.(tea.BatchMsg); ok {
+				for _, cmd := range batch {
+					l.cmds <- cmd
+				}
+				continue
+			}
+			if msg != nil {
+				msgs = append(msgs, msg)
+			}
+		default:
+			return msgs
+		}
+	}
+}
+
+// pump hands the messages of the pending commands to the top level
+// panel until no more commands are produced
+func (l *testLayout) pump() []tea.Msg {
+	all := []tea.Msg{}
+	for {
+		msgs := l.run()
+		if len(msgs) == 0 {
+			return all
+		}
+		for _, msg := range msgs {
+			l.top.HandleMessage(msg)
+		}
+		all = append(all, msgs...)
+	}
+}
+
+func (l *testLayout) focus(panel IPanel) {
+	l.top.HandleMessage(FocusRequestMsg{RequestedPath: panel.GetPath(), Relation: Self})
+	l.pump()
+}
+
+func (l *testLayout) press(key tcell.Key, r rune, modifiers tcell.ModMask) {
+	l.top.HandleMessage(testKeyMsg(key, r, modifiers))
+	l.pump()
+}
+
+func (l *testLayout) typeRunes(s string) {
+	for _, r := range s {
+		l.press(tcell.KeyRune, r, 0)
+	}
+}
+
+// focused returns the most deeply nested focused panel, or nil
+func (l *testLayout) focused() IPanel {
+	var focused IPanel
+	var walk func(panel IPanel)
+	walk = func(panel IPanel) {
+		if !panel.IsFocused() {
+			return
+		}
+		focused = panel
+		if list, ok := panel.(*ListPanel); ok {
+			for _, child := range list.Panels {
+				walk(child)
+			}
+		}
+	}
+	walk(l.top.ListPanel)
+	return focused
+}
+
+func (l *testLayout) leaf(panel *ShortCutPanel) *testLeaf {
+	return panel.Model.(*testLeaf)
+}
+
+// passKeys makes every leaf leave its key-strokes unused
+func (l *testLayout) passKeys() {
+	for _, panel := range []*ShortCutPanel{l.a, l.b, l.c, l.d} {
+		l.leaf(panel).passKeys = true
+	}
+}
+
+func (l *testLayout) draw() {
+	l.top.Draw(true)
+	l.screen.Show()
+}
+
+// row returns the characters on line y of the screen
+func (l *testLayout) row(y int) string {
+	return screenRow(l.screen, y)
+}
+
+func screenRow(screen tcell.SimulationScreen, y int) string {
+	cells, width, _ := screen.GetContents()
+	row := []rune{}
+	for x := 0; x < width; x++ {
+		if runes := cells[y*width+x].Runes; len(runes) > 0 {
+			row = append(row, runes[0])
+		} else {
+			row = append(row, ' ')
+		}
+	}
+	return string(row)
+}
+
+func testKeyMsg(key tcell.Key, r rune, modifiers tcell.ModMask) KeyMsg {
+	unused := true
+	direction := DownwardPropagation
+	return KeyMsg{EventKey: tcell.NewEventKey(key, r, modifiers), Unused: &unused, Direction: &direction}
+}
+
+func testKeyBinding(keys string, fn func() tea.Cmd, options ...KeyBindingOption) *KeyBinding {
+	options = append([]KeyBindingOption{WithKeys(keys), WithEnabled(true), WithFunc(fn)}, options...)
+	return NewKeyBinding(options...)
+}
+
+func runeKeyDef(r rune) KeyDef {
+	return KeyDef{Key: tcell.KeyRune, Rune: r}
+}
+
+func testKeyDefBinding(keyDef KeyDef, fn func() tea.Cmd, options ...KeyBindingOption) *KeyBinding {
+	options = append([]KeyBindingOption{WithKeyDef(keyDef), WithEnabled(true), WithFunc(fn)}, options...)
+	return NewKeyBinding(options...)
+}
@@ -0,0 +1,330 @@
+package peanutbutter
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+)
+
+// HelpSection is a group of key bindings of one panel
+// that share a category
+type HelpSection struct {
+	Panel       string
+	Category    string
+	KeyBindings []*KeyBinding
+}
+
+// HelpSections groups the bindings with help text that can currently
+// be reached by panel and category: the global key layers first, then
+// the panels of the focus path from the top level down, then the
+// global bindings of the other panels. Bindings of unnamed
+// panels are listed under "General"
+func (m *TopLevelListPanel) HelpSections() []HelpSection {
+	ctx := m.KeyContext()
+	sections := []HelpSection{}
+	seen := make(map[*KeyBinding]bool)
+	add := func(name string, keyBindings []*KeyBinding, onlyGlobal bool) {
+		if name == "" {
+			name = "General"
+		}
+		first := len(sections)
+		for _, keyBinding := range keyBindings {
+			if seen[keyBinding] || (onlyGlobal && !keyBinding.Global) {
+				continue
+			}
+			if !keyBinding.Enabled || !keyBinding.IsActive(&ctx) {
+				continue
+			}
+			if keyBinding.ShortHelp == "" && keyBinding.LongHelp == "" {
+				continue
+			}
+			seen[keyBinding] = true
+			i := first
+			for i < len(sections) && sections[i].Category != keyBinding.Category {
+				i++
+			}
+			if i == len(sections) {
+				sections = append(sections, HelpSection{Panel: name, Category: keyBinding.Category})
+			}
+			sections[i].KeyBindings = append(sections[i].KeyBindings, keyBinding)
+		}
+	}
+
+	add("Global", m.globalKeyLayers.ActiveKeyBindings(nil), false)
+	focusPath := FocusPath(m.ListPanel)
+	for _, panel := range focusPath {
+		add(panel.GetName(), activeKeyBindings(panel), false)
+	}
+	WalkPanels(m.ListPanel, func(panel IPanel) bool {
+		add(panel.GetName(), activeKeyBindings(panel), true)
+		return true
+	})
+	return sections
+}
+
+// ShowHelp opens the help overlay, covering the whole screen
+func (m *TopLevelListPanel) ShowHelp() {
+	if m.IsHelpShown() {
+		return
+	}
+	helpView := NewHelpView(m.HelpSections(), m.HideHelp)
+	helpView.ToggleKeyBinding = m.helpKeyBinding
+	m.help = NewShortCutPanel(helpView, WithTitle("Help"), WithName("Help"))
+	m.ShowOverlay(m.help, CenteredOverlay(1, 1), true)
+}
+
+func (m *TopLevelListPanel) HideHelp() {
+	if !m.IsHelpShown() {
+		return
+	}
+	m.HideOverlay(m.help)
+	m.help = nil
+}
+
+func (m *TopLevelListPanel) IsHelpShown() bool {
+	return m.help != nil && m.IsOverlayShown(m.help)
+}
+
+func (m *TopLevelListPanel) ToggleHelp() {
+	if m.IsHelpShown() {
+		m.HideHelp()
+	} else {
+		m.ShowHelp()
+	}
+}
+
+// WithHelpKeyBinding adds a global key binding that toggles the help overlay
+func WithHelpKeyBinding(keyBinding KeyBinding) TopLevelListPanelOption {
+	newKb := keyBinding
+	return func(m *TopLevelListPanel) {
+		newKb.Global = true
+		newKb.Func = func() tea.Cmd {
+			m.ToggleHelp()
+			return nil
+		}
+		m.ListPanel.AddKeyBinding(&newKb)
+		m.helpKeyBinding = &newKb
+	}
+}
+
+// HelpView is a leaf model that shows help sections with the keys,
+// ShortHelp and LongHelp of each binding. Up/Down, PgUp/PgDn and
+// Home/End scroll, "/" starts a search and Esc or "q" closes it
+type HelpView struct {
+	ToggleKeyBinding *KeyBinding // also closes the help, if set
+	sections         []HelpSection
+	close            func()
+	query            string
+	searching        bool
+	offset           int
+	width            int
+	height           int
+	redraw           bool
+	Style            HelpViewStyle
+}
+
+type HelpViewStyle struct {
+	Panel    lipgloss.Style
+	Category lipgloss.Style
+	Keys     lipgloss.Style
+	Help     lipgloss.Style
+	LongHelp lipgloss.Style
+	Search   lipgloss.Style
+}
+
+var DefaultHelpViewStyle = HelpViewStyle{
+	Panel:    Fg(Plt.Lavender()).Bold(true),
+	Category: Fg(Plt.Overlay2()).Italic(true),
+	Keys:     Fg(Plt.Mauve()).Bold(true),
+	Help:     lipgloss.NewStyle(),
+	LongHelp: Fg(Plt.Overlay1()),
+	Search:   lipgloss.NewStyle().Bold(true),
+}
+
+var _ ILeafModel = &HelpView{}
+var _ ILeafModelWithView = &HelpView{}
+
+// NewHelpView creates a help view of sections; close
+// is called when the user asks to close the help
+func NewHelpView(sections []HelpSection, close func()) *HelpView {
+	return &HelpView{
+		sections: sections,
+		close:    close,
+		redraw:   true,
+		Style:    DefaultHelpViewStyle,
+	}
+}
+
+func (h *HelpView) Init() tea.Cmd {
+	return nil
+}
+
+func helpMatches(keyBinding *KeyBinding, section HelpSection, query string) bool {
+	if query == "" {
+		return true
+	}
+	text := strings.ToLower(strings.Join([]string{keyBinding.renderKeys(), keyBinding.ShortHelp, keyBinding.LongHelp, section.Category}, " "))
+	return strings.Contains(text, strings.ToLower(query))
+}
+
+// lines renders the sections matching the search, without padding
+func (h *HelpView) lines() []string {
+	lines := []string{}
+	lastPanel := ""
+	for _, section := range h.sections {
+		keyBindings := []*KeyBinding{}
+		keysWidth := 0
+		for _, keyBinding := range section.KeyBindings {
+			if helpMatches(keyBinding, section, h.query) {
+				keyBindings = append(keyBindings, keyBinding)
+				keysWidth = max(keysWidth, runewidth.StringWidth(keyBinding.renderKeys()))
+			}
+		}
+		if len(keyBindings) == 0 {
+			continue
+		}
+		if len(lines) == 0 || section.Panel != lastPanel {
+			if len(lines) > 0 {
+				lines = append(lines, "")
+			}
+			lines = append(lines, h.Style.Panel.Render(section.Panel))
+			lastPanel = section.Panel
+		}
+		if section.Category != "" {
+			lines = append(lines, "  "+h.Style.Category.Render(section.Category))
+		}
+		for _, keyBinding := range keyBindings {
+			keys := keyBinding.renderKeys()
+			keys += strings.Repeat(" ", keysWidth-runewidth.StringWidth(keys))
+			lines = append(lines, "    "+h.Style.Keys.Render(keys)+"  "+h.Style.Help.Render(keyBinding.ShortHelp))
+			if keyBinding.LongHelp != "" && keyBinding.LongHelp != keyBinding.ShortHelp {
+				indent := strings.Repeat(" ", 4+keysWidth+2)
+				for _, line := range strings.Split(keyBinding.LongHelp, "\n") {
+					lines = append(lines, indent+h.Style.LongHelp.Render(line))
+				}
+			}
+		}
+	}
+	return lines
+}
+
+// pageHeight is the number of lines left for the help text
+// below the search line
+func (h *HelpView) pageHeight() int {
+	if h.searching || h.query != "" {
+		return max(h.height-1, 0)
+	}
+	return h.height
+}
+
+func (h *HelpView) scroll(delta int) {
+	h.offset += delta
+	h.offset = min(h.offset, len(h.lines())-h.pageHeight())
+	h.offset = max(h.offset, 0)
+	h.redraw = true
+}
+
+func (h *HelpView) handleSearchKey(msg KeyMsg) {
+	switch msg.Key() {
+	case tcell.KeyEsc:
+		h.query = ""
+		h.searching = false
+	case tcell.KeyEnter:
+		h.searching = false
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if h.query != "" {
+			runes := []rune(h.query)
+			h.query = string(runes[:len(runes)-1])
+		}
+	case tcell.KeyRune:
+		h.query += string(msg.Rune())
+	}
+	h.offset = 0
+	h.redraw = true
+}
+
+func (h *HelpView) handleKey(msg KeyMsg) {
+	if h.searching {
+		h.handleSearchKey(msg)
+		return
+	}
+	if h.ToggleKeyBinding != nil && h.ToggleKeyBinding.IsMatch(msg.EventKey) {
+		h.close()
+		return
+	}
+	switch msg.Key() {
+	case tcell.KeyEsc:
+		h.close()
+	case tcell.KeyUp:
+		h.scroll(-1)
+	case tcell.KeyDown:
+		h.scroll(1)
+	case tcell.KeyPgUp:
+		h.scroll(-h.pageHeight())
+	case tcell.KeyPgDn:
+		h.scroll(h.pageHeight())
+	case tcell.KeyHome:
+		h.scroll(-len(h.lines()))
+	case tcell.KeyEnd:
+		h.scroll(len(h.lines()))
+	case tcell.KeyRune:
+		switch msg.Rune() {
+		case 'q':
+			h.close()
+		case '/':
+			h.searching = true
+			h.redraw = true
+		case 'k':
+			h.scroll(-1)
+		case 'j':
+			h.scroll(1)
+		}
+	}
+}
+
+func (h *HelpView) Update(msg Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case ResizeMsg:
+		h.width = msg.Width
+		h.height = msg.Height
+		h.scroll(0)
+	case KeyMsg:
+		h.handleKey(msg)
+	case PasteMsg:
+		if h.searching {
+			h.query += strings.ReplaceAll(msg.Text, "\n", " ")
+			h.redraw = true
+		}
+	}
+	return nil
+}
+
+func (h *HelpView) NeedsRedraw() bool {
+	return h.redraw
+}
+
+func (h *HelpView) View() string {
+	h.redraw = false
+	if h.width <= 0 || h.height <= 0 {
+		return ""
+	}
+	lines := []string{}
+	if h.searching || h.query != "" {
+		lines = append(lines, h.Style.Search.Render(padLine("/"+h.query, h.width)))
+	}
+	helpLines := h.lines()
+	for i := h.offset; i < len(helpLines) && len(lines) < h.height; i++ {
+		line := helpLines[i]
+		if width := lipgloss.Width(line); width < h.width {
+			line += strings.Repeat(" ", h.width-width)
+		}
+		lines = append(lines, line)
+	}
+	for len(lines) < h.height {
+		lines = append(lines, strings.Repeat(" ", h.width))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,80 @@
+package peanutbutter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func newHelpLayout(t *testing.T) *testLayout {
+	t.Helper()
+	return newTestLayout(t, WithHelpKeyBinding(*NewKeyBinding(WithKeys("?"), WithEnabled(true), WithShortHelp("Help"))))
+}
+
+func TestHelpSections(t *testing.T) {
+	l := newHelpLayout(t)
+	l.b.AddKeyBinding(testKeyBinding("x", noop, WithShortHelp("Cut"), WithCategory("Edit")))
+	l.b.AddKeyBinding(testKeyBinding("y", noop, WithShortHelp("Copy"), WithCategory("Edit")))
+	l.b.AddKeyBinding(testKeyBinding("z", noop, WithShortHelp("Zoom")))
+	l.b.AddKeyBinding(testKeyBinding("w", noop))
+	l.a.AddKeyBinding(testKeyBinding("q", noop, WithShortHelp("Quit a")))
+	l.focus(l.b)
+
+	got := []string{}
+	for _, section := range l.top.HelpSections() {
+		got = append(got, section.Panel+"/"+section.Category)
+		if section.Panel == "b" && section.Category == "Edit" && len(section.KeyBindings) != 2 {
+			t.Errorf("b/Edit has %d bindings, want 2", len(section.KeyBindings))
+		}
+	}
+	// the help binding of the unnamed top level panel, then b;
+	// unfocused a is left out, as its binding is not global
+	want := []string{"General/", "b/Edit", "b/"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("sections %v, want %v", got, want)
+	}
+}
+
+func TestHelpKeyBindingToggles(t *testing.T) {
+	l := newHelpLayout(t)
+	l.focus(l.a)
+	l.press(tcell.KeyRune, '?', 0)
+	if !l.top.IsHelpShown() {
+		t.Fatal("help not shown")
+	}
+	l.press(tcell.KeyRune, '?', 0)
+	if l.top.IsHelpShown() {
+		t.Error("help still shown after pressing ? again")
+	}
+	if keyMsgs := l.leaf(l.a).keyMsgs(); len(keyMsgs) != 0 {
+		t.Errorf("focused a got %d keys", len(keyMsgs))
+	}
+
+	l.top.ToggleHelp()
+	l.press(tcell.KeyEsc, 0, 0)
+	if l.top.IsHelpShown() {
+		t.Error("help still shown after Esc")
+	}
+}
+
+func TestHelpViewSearch(t *testing.T) {
+	sections := []HelpSection{
+		{Panel: "b", Category: "Edit", KeyBindings: []*KeyBinding{
+			testKeyBinding("x", noop, WithShortHelp("Cut")),
+			testKeyBinding("y", noop, WithShortHelp("Copy")),
+		}},
+	}
+	helpView := NewHelpView(sections, func() {})
+	if lines := helpView.lines(); len(lines) != 4 {
+		t.Errorf("%d lines before searching, want 4", len(lines))
+	}
+	helpView.Update(testKeyMsg(tcell.KeyRune, '/', 0))
+	for _, r := range "cop" {
+		helpView.Update(testKeyMsg(tcell.KeyRune, r, 0))
+	}
+	lines := helpView.lines()
+	if len(lines) != 3 || !strings.Contains(lines[2], "Copy") {
+		t.Errorf("search for cop gives %q", lines)
+	}
+}
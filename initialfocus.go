@@ -0,0 +1,119 @@
+package peanutbutter
+
+// initialFocus identifies the panel that gets the focus at startup
+// by reference, by name or by path relative to the ListPanel
+type initialFocus struct {
+	panel IPanel
+	name  string
+	path  []int
+}
+
+// WithInitialFocus focuses panel once the top level panel has been
+// initialized and sized. On a nested ListPanel it picks the panel to
+// focus when that ListPanel is the initial focus of its parent
+func WithInitialFocus(panel IPanel) ListPanelOption {
+	return func(m *ListPanel) {
+		m.initialFocus = &initialFocus{panel: panel}
+	}
+}
+
+// WithInitialFocusName is WithInitialFocus for the first panel,
+// depth first, with the given name
+func WithInitialFocusName(name string) ListPanelOption {
+	return func(m *ListPanel) {
+		m.initialFocus = &initialFocus{name: name}
+	}
+}
+
+// WithInitialFocusPath is WithInitialFocus for the panel at path,
+// relative to the ListPanel
+func WithInitialFocusPath(path []int) ListPanelOption {
+	return func(m *ListPanel) {
+		m.initialFocus = &initialFocus{path: path}
+	}
+}
+
+// resolve finds the initial focus under list
+func (f *initialFocus) resolve(list *ListPanel) IPanel {
+	switch {
+	case f.panel != nil:
+		if FindPanelByPath(list, f.panel.GetPath()) == f.panel {
+			return f.panel
+		}
+		return nil
+	case f.name != "":
+		var found IPanel
+		WalkPanels(list, func(panel IPanel) bool {
+			if found != nil {
+				return false
+			}
+			if panel != IPanel(list) && panel.GetName() == f.name {
+				found = panel
+				return false
+			}
+			return true
+		})
+		return found
+	default:
+		return FindPanelByPath(list, append(append([]int{}, list.GetPath()...), f.path...))
+	}
+}
+
+// grantInitialFocus grants the initial focus after the first
+// ResizeMsg, once the panels have their paths and sizes
+func (m *TopLevelListPanel) grantInitialFocus() {
+	if m.started || m.cmds == nil {
+		return
+	}
+	m.started = true
+	m.grantFocus(m.initialFocusGrant())
+}
+
+// initialFocusGrant follows the initial focus of the top level
+// panel, and of the ListPanels it leads to, and returns the focus
+// grant for the panel it ends at, or for the first focusable leaf
+// under it if it is a container. Tabs on the way are selected
+func (m *TopLevelListPanel) initialFocusGrant() *FocusGrantMsg {
+	var target IPanel
+	for list := m.ListPanel; list != nil && list.initialFocus != nil; {
+		panel := list.initialFocus.resolve(list)
+		if panel == nil {
+			DebugPrintf("TopLevelListPanel: initial focus of %v not found\n", list.GetPath())
+			break
+		}
+		target = panel
+		list, _ = panel.(*ListPanel)
+	}
+	if target == nil {
+		return nil
+	}
+	// the tabs are selected first, the leaves of a hidden tab
+	// are not focusable
+	m.selectTabsTo(target)
+	if !IsLeafPanel(target) {
+		leaf := firstFocusable(LeafPanels(target))
+		if leaf == nil {
+			DebugPrintf("TopLevelListPanel: initial focus %v has no focusable panel\n", target.GetPath())
+			return nil
+		}
+		target = leaf
+	}
+	return &FocusGrantMsg{RoutePath: RoutePath{Path: target.GetPath()}, Relation: Self}
+}
+
+// selectTabsTo selects the tabs of the ZStacked ListPanels
+// on the way down to panel
+func (m *TopLevelListPanel) selectTabsTo(panel IPanel) {
+	path := panel.GetPath()
+	WalkPanels(m.ListPanel, func(p IPanel) bool {
+		if !IsPathPrefix(p.GetPath(), path) || IsSamePath(p.GetPath(), path) {
+			return false
+		}
+		if list, ok := p.(*ListPanel); ok && list.Layout.Orientation == ZStacked {
+			if index := path[len(list.GetPath())]; index != list.Selected {
+				m.cmds <- list.SetSelected(index)
+			}
+		}
+		return true
+	})
+}
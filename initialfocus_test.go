@@ -0,0 +1,96 @@
+package peanutbutter
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gdamore/tcell/v2"
+	tcellviews "github.com/gdamore/tcell/v2/views"
+)
+
+func TestInitialFocus(t *testing.T) {
+	tests := []struct {
+		name   string
+		option func(l *testLayout)
+		want   func(l *testLayout) IPanel
+	}{
+		{"by panel", func(l *testLayout) {
+			WithInitialFocus(l.b)(l.top.ListPanel)
+		}, func(l *testLayout) IPanel { return l.b }},
+		{"by name", func(l *testLayout) {
+			WithInitialFocusName("b")(l.top.ListPanel)
+		}, func(l *testLayout) IPanel { return l.b }},
+		{"by path", func(l *testLayout) {
+			WithInitialFocusPath([]int{1, 0})(l.top.ListPanel)
+		}, func(l *testLayout) IPanel { return l.b }},
+		{"container", func(l *testLayout) {
+			WithInitialFocus(l.tabs)(l.top.ListPanel)
+		}, func(l *testLayout) IPanel { return l.c }},
+		{"nested", func(l *testLayout) {
+			WithInitialFocus(l.right)(l.top.ListPanel)
+			WithInitialFocusPath([]int{1})(l.right)
+		}, func(l *testLayout) IPanel { return l.c }},
+		{"unknown", func(l *testLayout) {
+			WithInitialFocusName("nope")(l.top.ListPanel)
+		}, func(l *testLayout) IPanel { return nil }},
+	}
+	for _, test := range tests {
+		l := buildTestLayout()
+		test.option(l)
+		l.start(t)
+		if want := test.want(l); l.focused() != want {
+			t.Errorf("%s: focus on %v, want %v", test.name, panelName(l.focused()), panelName(want))
+		}
+	}
+}
+
+func TestInitialFocusSelectsTab(t *testing.T) {
+	l := buildTestLayout()
+	WithInitialFocusName("d")(l.top.ListPanel)
+	l.start(t)
+	if l.focused() != l.d || l.tabs.GetSelected() != l.d || l.d.IsInHiddenTab() {
+		t.Errorf("focus on %v, %v selected", panelName(l.focused()), panelName(l.tabs.GetSelected()))
+	}
+}
+
+func TestInitialFocusContainerInHiddenTab(t *testing.T) {
+	l := &testLayout{a: newTestPanel("a"), b: newTestPanel("b"), c: newTestPanel("c"), d: newTestPanel("d")}
+	inner := NewListPanel([]IPanel{l.b, l.d}, Layout{Orientation: Vertical, Dimensions: []Dimension{{Ratio: 0.5}, {}}}, WithListPanelName("inner"))
+	l.tabs = NewListPanel([]IPanel{l.c, inner}, Layout{Orientation: ZStacked}, WithListPanelName("tabs"))
+	root := NewListPanel([]IPanel{l.a, l.tabs}, Layout{Orientation: Horizontal, Dimensions: []Dimension{{Ratio: 0.5}, {}}})
+	WithInitialFocus(inner)(root)
+	l.top = NewTopLevelListPanel(root)
+	l.start(t)
+	if l.focused() != l.b || l.tabs.GetSelected() != inner {
+		t.Errorf("focus on %v, %v selected", panelName(l.focused()), panelName(l.tabs.GetSelected()))
+	}
+}
+
+func TestInitialFocusWaitsForSize(t *testing.T) {
+	l := buildTestLayout()
+	WithInitialFocus(l.b)(l.top.ListPanel)
+	l.screen = tcell.NewSimulationScreen("")
+	if err := l.screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	l.cmds = make(chan tea.Cmd, 1000)
+	l.top.SetView(tcellviews.NewViewPort(l.screen, 0, 0, -1, -1))
+	l.top.Init(l.cmds)
+	l.pump()
+	if l.focused() != nil {
+		t.Fatalf("focus on %v before the first resize", panelName(l.focused()))
+	}
+	l.top.HandleMessage(ResizeMsg{Width: 80, Height: 24})
+	l.pump()
+	if l.focused() != l.b {
+		t.Fatalf("focus on %v, want b", panelName(l.focused()))
+	}
+
+	// later resizes leave the focus alone
+	l.focus(l.a)
+	l.top.HandleMessage(ResizeMsg{Width: 60, Height: 20})
+	l.pump()
+	if l.focused() != l.a {
+		t.Errorf("focus on %v after resizing, want a", panelName(l.focused()))
+	}
+}
@@ -39,6 +39,12 @@ type ILeafModelWithDraw interface {
 	Draw(force bool, view *tcellviews.ViewPort) bool
 }
 
+// ILeafModelWithTabBadge is implemented by models that show a badge,
+// such as a modified marker, next to their name in a tab bar
+type ILeafModelWithTabBadge interface {
+	TabBadge() string
+}
+
 type PanelCenter struct {
 	X    int
 	Y    int
@@ -53,10 +59,30 @@ type IPanel interface {
 	SetPath(path []int)
 	HandleMessage(msg Msg)
 	SetView(view *tcellviews.ViewPort)
+	GetView() *tcellviews.ViewPort
 	Draw(force bool) bool
 	Init(cmds chan tea.Cmd)
 	GetName() string
 	SetTabHidden(hidden bool)
 	IsInHiddenTab() bool
 	AddKeyBinding(kb *KeyBinding)
+	GetKeyBindings() []*KeyBinding
+}
+
+// IPanelWithTabBadge is implemented by panels that show a badge
+// next to their name when they are a tab of a ZStacked ListPanel
+type IPanelWithTabBadge interface {
+	GetTabBadge() string
+}
+
+// IPanelWithEnabled is implemented by panels that can be disabled
+// Focus movement skips disabled panels
+type IPanelWithEnabled interface {
+	IsEnabled() bool
+}
+
+// IPanelWithChildren is implemented by panels that house other panels,
+// such as ListPanel
+type IPanelWithChildren interface {
+	GetChildren() []IPanel
 }
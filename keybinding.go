@@ -15,12 +15,20 @@ type KeyDef struct {
 }
 
 type KeyBinding struct {
-	KeyDefs   []KeyDef
-	ShortHelp string
-	LongHelp  string
-	Enabled   bool
-	Override  bool
-	Func      func() tea.Cmd
+	ActionID string // Stable ID used to remap the binding from a KeyMapConfig
+	KeyDefs  []KeyDef
+	// KeySequences are multi-key alternatives, e.g. "Ctrl+W h". A key
+	// that does not continue a pending sequence cancels it and is
+	// dropped, like Esc, rather than being delivered on its own
+	KeySequences [][]KeyDef
+	ShortHelp    string
+	LongHelp     string
+	Category     string // Groups the binding in the help overlay
+	Enabled      bool
+	Override     bool
+	Global       bool                      // Considered regardless of which panel is focused
+	When         func(ctx KeyContext) bool // If set, the binding only applies while it returns true
+	Func         func() tea.Cmd
 }
 
 func NewKeyBinding(opts ...KeyBindingOption) *KeyBinding {
@@ -79,6 +87,34 @@ func WithKeyDefs(keyDefs []KeyDef) KeyBindingOption {
 	}
 }
 
+func WithActionID(actionID string) KeyBindingOption {
+	return func(keybinding *KeyBinding) {
+		keybinding.ActionID = actionID
+	}
+}
+
+func WithGlobal(global bool) KeyBindingOption {
+	return func(keybinding *KeyBinding) {
+		keybinding.Global = global
+	}
+}
+
+func WithWhen(when func(ctx KeyContext) bool) KeyBindingOption {
+	return func(keybinding *KeyBinding) {
+		keybinding.When = when
+	}
+}
+
+// WithWhenExpr makes the binding apply only while the when
+// expression holds, see WhenExpr. It panics if the expression is invalid
+func WithWhenExpr(expr string) KeyBindingOption {
+	when, err := ParseWhenExpr(expr)
+	if err != nil {
+		panic(err)
+	}
+	return WithWhen(when.Eval)
+}
+
 func WithShortHelp(shortHelp string) KeyBindingOption {
 	return func(keybinding *KeyBinding) {
 		keybinding.ShortHelp = shortHelp
@@ -91,21 +127,55 @@ func WithLongHelp(longHelp string) KeyBindingOption {
 	}
 }
 
+func WithCategory(category string) KeyBindingOption {
+	return func(keybinding *KeyBinding) {
+		keybinding.Category = category
+	}
+}
+
 func WithKeyDef(keyDef KeyDef) KeyBindingOption {
 	return func(keybinding *KeyBinding) {
 		keybinding.KeyDefs = append(keybinding.KeyDefs, keyDef)
 	}
 }
 
+// WithKeySequence adds a sequence of keys that have to be
+// pressed one after the other to trigger the binding
+func WithKeySequence(keyDefs ...KeyDef) KeyBindingOption {
+	return func(keybinding *KeyBinding) {
+		keybinding.KeySequences = append(keybinding.KeySequences, keyDefs)
+	}
+}
+
 func (keybinding *KeyBinding) IsEnabled() bool {
-	return keybinding.Enabled && len(keybinding.KeyDefs) > 0
+	return keybinding.Enabled && (len(keybinding.KeyDefs) > 0 || len(keybinding.KeySequences) > 0)
+}
+
+// IsActive returns true if the binding has no when clause or
+// the clause holds in ctx. A nil ctx is treated as an empty context
+func (keybinding *KeyBinding) IsActive(ctx *KeyContext) bool {
+	if keybinding.When == nil {
+		return true
+	}
+	if ctx == nil {
+		return keybinding.When(KeyContext{})
+	}
+	return keybinding.When(*ctx)
+}
+
+func KeyDefFromEventKey(eventKey *tcell.EventKey) KeyDef {
+	return KeyDef{Key: eventKey.Key(), Modifiers: eventKey.Modifiers(), Rune: eventKey.Rune()}
 }
 
 func (keyDef *KeyDef) Matches(eventKey *tcell.EventKey) bool {
-	if eventKey.Key() == tcell.KeyRune {
-		return eventKey.Rune() == keyDef.Rune && eventKey.Modifiers() == keyDef.Modifiers
+	return keyDef.matchesKeyDef(KeyDefFromEventKey(eventKey))
+}
+
+func (keyDef *KeyDef) matchesKeyDef(other KeyDef) bool {
+	if other.Key == tcell.KeyRune {
+		return other.Rune == keyDef.Rune && other.Modifiers == keyDef.Modifiers
 	}
-	return eventKey.Key() == keyDef.Key && eventKey.Modifiers() == keyDef.Modifiers
+	return other.Key == keyDef.Key && other.Modifiers == keyDef.Modifiers
 }
 
 func (keybinding *KeyBinding) IsMatch(eventKey *tcell.EventKey) bool {
@@ -117,29 +187,67 @@ func (keybinding *KeyBinding) IsMatch(eventKey *tcell.EventKey) bool {
 	return false
 }
 
+// KeyBindingsHandler runs the first enabled binding that matches the
+// key-stroke and marks the key as used.
+// Bindings whose when clause does not hold in the context carried by
+// the KeyMsg are skipped.
+// If the KeyMsg carries a KeySequenceState, key sequences are matched
+// too: a key that continues a sequence is used up without running
+// anything, and while a sequence is pending only sequences are matched
 func KeyBindingsHandler(keyBindings []*KeyBinding, msg KeyMsg, onlyOverrides bool) tea.Cmd {
 	DebugPrintf("KeyBindingsHandler received message from child: %T %+v\n", msg, msg)
+	sequence := msg.Sequence
+	pending := sequence.IsPending()
 	for _, keyBinding := range keyBindings {
 		isValid := (onlyOverrides && keyBinding.Override) || !onlyOverrides
-		if keyBinding.IsMatch(msg.EventKey) && isValid {
-			if keyBinding.Enabled {
-				if keyBinding.Func != nil {
-					cmd := keyBinding.Func()
-					msg.SetUsed()
-					return cmd
-				}
-			}
+		if !isValid || !keyBinding.Enabled || keyBinding.Func == nil || !keyBinding.IsActive(msg.Context) {
+			continue
+		}
+		if !pending && keyBinding.IsMatch(msg.EventKey) {
+			cmd := keyBinding.Func()
+			msg.SetUsed()
+			return cmd
+		}
+		if sequence == nil {
+			continue
+		}
+		switch keyBinding.matchSequence(sequence.Pending, KeyDefFromEventKey(msg.EventKey)) {
+		case sequenceCompleteMatch:
+			sequence.completed = true
+			msg.SetUsed()
+			return keyBinding.Func()
+		case sequencePrefixMatch:
+			sequence.extended = true
+			msg.SetUsed()
+			return nil
 		}
 	}
 	//msg.SetUnused()
 	return nil
 }
 
+// GlobalKeyBindingsHandler is like KeyBindingsHandler, but only
+// considers the bindings marked as global
+func GlobalKeyBindingsHandler(keyBindings []*KeyBinding, msg KeyMsg) tea.Cmd {
+	globalBindings := []*KeyBinding{}
+	for _, keyBinding := range keyBindings {
+		if keyBinding.Global {
+			globalBindings = append(globalBindings, keyBinding)
+		}
+	}
+	return KeyBindingsHandler(globalBindings, msg, false)
+}
+
 func (keyBinding *KeyBinding) SetFunc(fn func() tea.Cmd) *KeyBinding {
 	keyBinding.Func = fn
 	return keyBinding
 }
 
+func (keyBinding *KeyBinding) SetGlobal(global bool) *KeyBinding {
+	keyBinding.Global = global
+	return keyBinding
+}
+
 func (keyBinding *KeyBinding) SetShortHelp(shortHelp string) *KeyBinding {
 	keyBinding.ShortHelp = shortHelp
 	return keyBinding
@@ -191,11 +299,29 @@ func renderKeyDefs(keyDefs []KeyDef) string {
 	return strings.Join(keys, "/")
 }
 
+// renderKeys renders the single keys and key sequences of the binding
+func (keybinding *KeyBinding) renderKeys() string {
+	keys := []string{}
+	if len(keybinding.KeyDefs) > 0 {
+		keys = append(keys, renderKeyDefs(keybinding.KeyDefs))
+	}
+	for _, sequence := range keybinding.KeySequences {
+		keys = append(keys, RenderKeySequence(sequence))
+	}
+	return strings.Join(keys, "/")
+}
+
 func ShortHelpTexts(keybindings []*KeyBinding) []string {
+	return ShortHelpTextsInContext(keybindings, nil)
+}
+
+// ShortHelpTextsInContext is like ShortHelpTexts but leaves out bindings
+// whose when clause does not hold in ctx. A nil ctx leaves none out
+func ShortHelpTextsInContext(keybindings []*KeyBinding, ctx *KeyContext) []string {
 	helpTexts := []string{}
 	for _, keybinding := range keybindings {
-		if keybinding.ShortHelp != "" && keybinding.Enabled {
-			helptext := fmt.Sprintf("%s: %s", renderKeyDefs(keybinding.KeyDefs), keybinding.ShortHelp)
+		if keybinding.ShortHelp != "" && keybinding.Enabled && (ctx == nil || keybinding.IsActive(ctx)) {
+			helptext := fmt.Sprintf("%s: %s", keybinding.renderKeys(), keybinding.ShortHelp)
 			helpTexts = append(helpTexts, helptext)
 		}
 	}
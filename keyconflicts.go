@@ -0,0 +1,176 @@
+package peanutbutter
+
+import (
+	"fmt"
+)
+
+type KeyBindingConflictKind int
+
+const (
+	// An Override binding of a ListPanel takes the key before
+	// a descendant on the focus path gets to see it
+	ShadowedByOverride KeyBindingConflictKind = iota
+	// Two panels on the same focus path bind the same key
+	SameFocusPath
+	// Two panels claim the same key with global bindings
+	GlobalConflict
+	// One panel binds the same key twice
+	SamePanel
+)
+
+func (k KeyBindingConflictKind) String() string {
+	switch k {
+	case ShadowedByOverride:
+		return "shadowed by override"
+	case SameFocusPath:
+		return "bound twice on focus path"
+	case GlobalConflict:
+		return "claimed by two global bindings"
+	case SamePanel:
+		return "bound twice in panel"
+	}
+	return fmt.Sprintf("KeyBindingConflictKind(%d)", int(k))
+}
+
+// KeyBindingOwner identifies a key binding and the panel it belongs to
+type KeyBindingOwner struct {
+	Panel   IPanel
+	Path    []int
+	Name    string
+	Binding *KeyBinding
+}
+
+func (o KeyBindingOwner) String() string {
+	return fmt.Sprintf("%q %v (%s)", o.Name, o.Path, o.Binding.ShortHelp)
+}
+
+// KeyBindingConflict describes two bindings competing for a key.
+// Winner is the binding that gets the key, Loser the one that
+// never sees it. A key that is a prefix of a key sequence is also
+// reported, as the sequence can then never be started
+type KeyBindingConflict struct {
+	Kind   KeyBindingConflictKind
+	Keys   string
+	Winner KeyBindingOwner
+	Loser  KeyBindingOwner
+}
+
+func (c KeyBindingConflict) String() string {
+	return fmt.Sprintf("%s: %s: %v wins over %v", c.Keys, c.Kind, c.Winner, c.Loser)
+}
+
+// triggers returns the keys and key sequences of the binding,
+// single keys as sequences of length one
+func (keybinding *KeyBinding) triggers() [][]KeyDef {
+	triggers := [][]KeyDef{}
+	for _, keyDef := range keybinding.KeyDefs {
+		triggers = append(triggers, []KeyDef{keyDef})
+	}
+	return append(triggers, keybinding.KeySequences...)
+}
+
+// overlappingKeys returns the first key or key sequence of a
+// that is equal to, or a prefix of, one of b or vice versa
+func overlappingKeys(a *KeyBinding, b *KeyBinding) (string, bool) {
+	for _, ta := range a.triggers() {
+		for _, tb := range b.triggers() {
+			shorter, longer := ta, tb
+			if len(shorter) > len(longer) {
+				shorter, longer = longer, shorter
+			}
+			overlap := true
+			for i := range shorter {
+				if !shorter[i].matchesKeyDef(longer[i]) {
+					overlap = false
+					break
+				}
+			}
+			if overlap {
+				return RenderKeySequence(shorter), true
+			}
+		}
+	}
+	return "", false
+}
+
+func makeKeyBindingOwners(panel IPanel) []KeyBindingOwner {
+	owners := []KeyBindingOwner{}
+	for _, keyBinding := range activeKeyBindings(panel) {
+		if !keyBinding.IsEnabled() || keyBinding.Func == nil {
+			continue
+		}
+		owners = append(owners, KeyBindingOwner{
+			Panel:   panel,
+			Path:    panel.GetPath(),
+			Name:    panel.GetName(),
+			Binding: keyBinding,
+		})
+	}
+	return owners
+}
+
+// FindKeyBindingConflicts walks the panel tree under root and reports
+// every pair of enabled bindings that compete for the same key:
+// bindings of a panel and its ancestors (which all lie on one focus
+// path), global bindings of any two panels, and duplicates within a
+// panel. For global bindings the winner is the one earlier in path
+// order, which is the precedence when neither panel is focused.
+// Key layers pushed at the time of the call are taken into account
+func FindKeyBindingConflicts(root IPanel) []KeyBindingConflict {
+	conflicts := []KeyBindingConflict{}
+	owners := make(map[IPanel][]KeyBindingOwner)
+	panels := []IPanel{}
+	WalkPanels(root, func(panel IPanel) bool {
+		owners[panel] = makeKeyBindingOwners(panel)
+		panels = append(panels, panel)
+		return true
+	})
+
+	compare := func(winner KeyBindingOwner, loser KeyBindingOwner, kind KeyBindingConflictKind) {
+		if keys, ok := overlappingKeys(winner.Binding, loser.Binding); ok {
+			conflicts = append(conflicts, KeyBindingConflict{Kind: kind, Keys: keys, Winner: winner, Loser: loser})
+		}
+	}
+
+	for _, panel := range panels {
+		mine := owners[panel]
+		for i := range mine {
+			for j := i + 1; j < len(mine); j++ {
+				compare(mine[i], mine[j], SamePanel)
+			}
+		}
+	}
+
+	for _, descendant := range panels {
+		for _, ancestor := range panels {
+			if ancestor == descendant || !IsPathPrefix(ancestor.GetPath(), descendant.GetPath()) {
+				continue
+			}
+			for _, a := range owners[ancestor] {
+				for _, d := range owners[descendant] {
+					if a.Binding.Override {
+						compare(a, d, ShadowedByOverride)
+					} else {
+						compare(d, a, SameFocusPath)
+					}
+				}
+			}
+		}
+	}
+
+	for i, first := range panels {
+		for _, second := range panels[i+1:] {
+			if IsPathPrefix(first.GetPath(), second.GetPath()) {
+				continue // already reported as a focus path conflict
+			}
+			for _, a := range owners[first] {
+				for _, b := range owners[second] {
+					if a.Binding.Global && b.Binding.Global {
+						compare(a, b, GlobalConflict)
+					}
+				}
+			}
+		}
+	}
+	return conflicts
+}
@@ -0,0 +1,70 @@
+package peanutbutter
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func noop() tea.Cmd { return nil }
+
+func conflictKinds(conflicts []KeyBindingConflict) map[KeyBindingConflictKind][]KeyBindingConflict {
+	kinds := make(map[KeyBindingConflictKind][]KeyBindingConflict)
+	for _, conflict := range conflicts {
+		kinds[conflict.Kind] = append(kinds[conflict.Kind], conflict)
+	}
+	return kinds
+}
+
+func TestFindKeyBindingConflicts(t *testing.T) {
+	l := newTestLayout(t)
+	override := testKeyBinding("q", noop, WithShortHelp("quit right"))
+	override.Override = true
+	l.right.AddKeyBinding(override)
+	l.b.AddKeyBinding(testKeyBinding("q", noop, WithShortHelp("quit b")))
+	l.b.AddKeyBinding(testKeyBinding("g g", noop, WithShortHelp("top")))
+	l.b.AddKeyBinding(testKeyBinding("g", noop, WithShortHelp("go")))
+	l.a.AddKeyBinding(testKeyBinding("Ctrl+L", noop, WithGlobal(true), WithShortHelp("a")))
+	l.d.AddKeyBinding(testKeyBinding("Ctrl+L", noop, WithGlobal(true), WithShortHelp("d")))
+
+	kinds := conflictKinds(FindKeyBindingConflicts(l.top.ListPanel))
+	if c := kinds[ShadowedByOverride]; len(c) != 1 || c[0].Winner.Binding != override || c[0].Loser.Panel != l.b {
+		t.Errorf("shadowed by override: %v", c)
+	}
+	if c := kinds[SamePanel]; len(c) != 1 || c[0].Winner.Panel != l.b {
+		t.Errorf("same panel: %v", c)
+	}
+	if c := kinds[GlobalConflict]; len(c) != 1 || c[0].Winner.Panel != l.a || c[0].Loser.Panel != l.d {
+		t.Errorf("global: %v", c)
+	}
+	if c := kinds[SameFocusPath]; len(c) != 0 {
+		t.Errorf("same focus path: %v", c)
+	}
+}
+
+func TestFindKeyBindingConflictsOnFocusPath(t *testing.T) {
+	l := newTestLayout(t)
+	l.right.AddKeyBinding(testKeyBinding("x", noop, WithShortHelp("right")))
+	l.b.AddKeyBinding(testKeyBinding("x", noop, WithShortHelp("b")))
+	l.a.AddKeyBinding(testKeyBinding("x", noop, WithShortHelp("a")))
+
+	conflicts := FindKeyBindingConflicts(l.top.ListPanel)
+	if len(conflicts) != 1 || conflicts[0].Kind != SameFocusPath || conflicts[0].Winner.Panel != l.b {
+		t.Errorf("got %v", conflicts)
+	}
+}
+
+func TestKeyBindingConflictWarnings(t *testing.T) {
+	reported := []KeyBindingConflict{}
+	l := &testLayout{a: newTestPanel("a"), b: newTestPanel("b")}
+	l.a.AddKeyBinding(testKeyBinding("x", noop))
+	l.a.AddKeyBinding(testKeyBinding("x", noop))
+	root := NewListPanel([]IPanel{l.a, l.b}, Layout{Orientation: Horizontal, Dimensions: []Dimension{{Ratio: 0.5}, {}}})
+	l.top = NewTopLevelListPanel(root, WithKeyBindingConflictWarnings(func(conflicts []KeyBindingConflict) {
+		reported = append(reported, conflicts...)
+	}))
+	l.start(t)
+	if len(reported) != 1 || reported[0].Kind != SamePanel {
+		t.Errorf("reported %v", reported)
+	}
+}
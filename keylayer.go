@@ -0,0 +1,129 @@
+package peanutbutter
+
+import "fmt"
+
+// KeyLayer is a named set of key bindings, such as the bindings
+// of a vim-style "insert" mode. While a layer is active its
+// bindings take precedence over the bindings below it
+type KeyLayer struct {
+	Name        string
+	KeyBindings []*KeyBinding
+	Fallthrough bool // if true, the bindings below this layer still apply
+}
+
+func NewKeyLayer(name string, fallsThrough bool, keyBindings ...*KeyBinding) *KeyLayer {
+	return &KeyLayer{
+		Name:        name,
+		KeyBindings: keyBindings,
+		Fallthrough: fallsThrough,
+	}
+}
+
+// KeyLayerStack holds the key layers pushed on a panel
+// The topmost layer is the active one and its name is the
+// current mode of the panel
+type KeyLayerStack struct {
+	layers []*KeyLayer
+	keyMap KeyMapConfig // applied to the layers pushed after the keymap
+}
+
+type iPanelWithKeyLayerStack interface {
+	keyLayerStack() *KeyLayerStack
+}
+
+func (s *KeyLayerStack) keyLayerStack() *KeyLayerStack {
+	return s
+}
+
+func (s *KeyLayerStack) PushKeyLayer(layer *KeyLayer) {
+	if s.keyMap != nil {
+		if _, err := s.keyMap.ApplyToKeyBindings(layer.KeyBindings); err != nil {
+			DebugPrintf("KeyLayerStack: %v\n", err)
+		}
+	}
+	s.layers = append(s.layers, layer)
+}
+
+// allKeyBindings returns the bindings of every pushed layer
+func (s *KeyLayerStack) allKeyBindings() []*KeyBinding {
+	keyBindings := []*KeyBinding{}
+	for _, layer := range s.layers {
+		keyBindings = append(keyBindings, layer.KeyBindings...)
+	}
+	return keyBindings
+}
+
+// PopKeyLayer removes the active layer and returns it,
+// or nil if there are no layers
+func (s *KeyLayerStack) PopKeyLayer() *KeyLayer {
+	if len(s.layers) == 0 {
+		return nil
+	}
+	layer := s.layers[len(s.layers)-1]
+	s.layers = s.layers[:len(s.layers)-1]
+	return layer
+}
+
+func (s *KeyLayerStack) ActiveKeyLayer() *KeyLayer {
+	if len(s.layers) == 0 {
+		return nil
+	}
+	return s.layers[len(s.layers)-1]
+}
+
+// Mode returns the name of the active layer, or "" if no layer is pushed
+func (s *KeyLayerStack) Mode() string {
+	if layer := s.ActiveKeyLayer(); layer != nil {
+		return layer.Name
+	}
+	return ""
+}
+
+// ActiveKeyBindings returns the bindings that apply given the layers
+// on the stack, in order of precedence. The base bindings come last and
+// only apply if every pushed layer falls through
+func (s *KeyLayerStack) ActiveKeyBindings(base []*KeyBinding) []*KeyBinding {
+	if len(s.layers) == 0 {
+		return base
+	}
+	keyBindings := []*KeyBinding{}
+	for i := len(s.layers) - 1; i >= 0; i-- {
+		keyBindings = append(keyBindings, s.layers[i].KeyBindings...)
+		if !s.layers[i].Fallthrough {
+			return keyBindings
+		}
+	}
+	return append(keyBindings, base...)
+}
+
+// ShortHelpTexts returns the short help texts of the active bindings
+// that apply in ctx, preceded by the current mode if a layer is pushed
+func (s *KeyLayerStack) ShortHelpTexts(base []*KeyBinding, ctx *KeyContext) []string {
+	helpTexts := []string{}
+	if mode := s.Mode(); mode != "" {
+		helpTexts = append(helpTexts, fmt.Sprintf("-- %s --", mode))
+	}
+	return append(helpTexts, ShortHelpTextsInContext(s.ActiveKeyBindings(base), ctx)...)
+}
+
+type iPanelWithKeyLayers interface {
+	ActiveKeyBindings(base []*KeyBinding) []*KeyBinding
+}
+
+// activeKeyBindings returns the bindings of the panel that apply
+// given the key layers pushed on it
+func activeKeyBindings(panel IPanel) []*KeyBinding {
+	if layered, ok := panel.(iPanelWithKeyLayers); ok {
+		return layered.ActiveKeyBindings(panel.GetKeyBindings())
+	}
+	return panel.GetKeyBindings()
+}
+
+// panelShortHelpTexts returns the short help texts of the panel that apply
+// in ctx, preceded by the mode of the panel if a layer is pushed on it
+func panelShortHelpTexts(panel IPanel, ctx *KeyContext) []string {
+	if layered, ok := panel.(iPanelWithKeyLayerStack); ok {
+		return layered.keyLayerStack().ShortHelpTexts(panel.GetKeyBindings(), ctx)
+	}
+	return ShortHelpTextsInContext(panel.GetKeyBindings(), ctx)
+}
@@ -0,0 +1,91 @@
+package peanutbutter
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestKeyLayerStackActiveKeyBindings(t *testing.T) {
+	base := testKeyDefBinding(runeKeyDef('x'), nil)
+	insert := testKeyDefBinding(runeKeyDef('i'), nil)
+	visual := testKeyDefBinding(runeKeyDef('v'), nil)
+	var stack KeyLayerStack
+	if stack.Mode() != "" || len(stack.ActiveKeyBindings([]*KeyBinding{base})) != 1 {
+		t.Fatal("empty stack should leave the base bindings")
+	}
+
+	stack.PushKeyLayer(NewKeyLayer("insert", false, insert))
+	if got := stack.ActiveKeyBindings([]*KeyBinding{base}); len(got) != 1 || got[0] != insert {
+		t.Errorf("insert layer: got %v", got)
+	}
+	stack.PushKeyLayer(NewKeyLayer("visual", true, visual))
+	if stack.Mode() != "visual" {
+		t.Errorf("mode %q", stack.Mode())
+	}
+	if got := stack.ActiveKeyBindings([]*KeyBinding{base}); len(got) != 2 || got[0] != visual || got[1] != insert {
+		t.Errorf("falling through to insert: got %v", got)
+	}
+
+	if popped := stack.PopKeyLayer(); popped.Name != "visual" {
+		t.Errorf("popped %q", popped.Name)
+	}
+	stack.PopKeyLayer()
+	if stack.PopKeyLayer() != nil || stack.Mode() != "" {
+		t.Error("stack should be empty")
+	}
+}
+
+func TestPanelKeyLayer(t *testing.T) {
+	l := newTestLayout(t)
+	fired := ""
+	record := func(name string) func() tea.Cmd {
+		return func() tea.Cmd {
+			fired += name
+			return nil
+		}
+	}
+	l.a.AddKeyBinding(testKeyDefBinding(runeKeyDef('x'), record("x"), WithShortHelp("delete")))
+	l.a.PushKeyLayer(NewKeyLayer("insert", false, testKeyDefBinding(KeyDef{Key: tcell.KeyEsc}, record("esc"), WithShortHelp("normal"))))
+	l.passKeys()
+	l.focus(l.a)
+
+	l.press(tcell.KeyRune, 'x', 0)
+	l.press(tcell.KeyEsc, 0, 0)
+	if fired != "esc" {
+		t.Errorf("fired %q in insert mode", fired)
+	}
+	if got := l.a.ShortHelpTexts(l.a.KeyBindings, nil); len(got) != 2 || got[0] != "-- insert --" {
+		t.Errorf("help %q", got)
+	}
+
+	l.a.PopKeyLayer()
+	l.press(tcell.KeyRune, 'x', 0)
+	if fired != "escx" {
+		t.Errorf("fired %q after leaving insert mode", fired)
+	}
+}
+
+func TestGlobalKeyLayer(t *testing.T) {
+	l := newTestLayout(t)
+	fired := false
+	l.top.HandleMessage(PushGlobalKeyLayerMsg{Layer: NewKeyLayer("resize", false, testKeyDefBinding(runeKeyDef('h'), func() tea.Cmd {
+		fired = true
+		return nil
+	}))})
+	if l.top.GlobalMode() != "resize" {
+		t.Fatalf("global mode %q", l.top.GlobalMode())
+	}
+	l.focus(l.a)
+	l.press(tcell.KeyRune, 'h', 0)
+	if !fired || len(l.leaf(l.a).keyMsgs()) != 0 {
+		t.Errorf("fired %v, focused leaf got %d keys", fired, len(l.leaf(l.a).keyMsgs()))
+	}
+
+	l.top.HandleMessage(PopGlobalKeyLayerMsg{})
+	l.press(tcell.KeyRune, 'h', 0)
+	if l.top.GlobalMode() != "" || len(l.leaf(l.a).keyMsgs()) != 1 {
+		t.Error("key should reach the focused leaf once the layer is popped")
+	}
+}
@@ -0,0 +1,259 @@
+package peanutbutter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// KeyMapConfig maps the action IDs of key bindings to the keys that
+// should trigger them, written as human readable key strings.
+// A string with several space separated keys is a key sequence, e.g.
+//
+//	{
+//	  "editor.save": ["Ctrl+S"],
+//	  "window.left": ["Ctrl+W h", "Alt+Left"]
+//	}
+//
+// An empty list unbinds the action. In a file, action IDs may also
+// be written as nested tables, e.g. [editor] save = ["Ctrl+S"] in TOML
+type KeyMapConfig map[string][]string
+
+type KeyMapFormat int
+
+const (
+	KeyMapJSON KeyMapFormat = iota
+	KeyMapTOML
+	KeyMapYAML
+)
+
+// KeyMapFormatOf picks the format of a keymap file by its extension:
+// .json, .toml, .yaml or .yml
+func KeyMapFormatOf(path string) (KeyMapFormat, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return KeyMapJSON, nil
+	case ".toml":
+		return KeyMapTOML, nil
+	case ".yaml", ".yml":
+		return KeyMapYAML, nil
+	}
+	return 0, fmt.Errorf("keymap: unknown file format %q", filepath.Ext(path))
+}
+
+// KeyMapError describes an entry of a KeyMapConfig that could not be applied
+type KeyMapError struct {
+	ActionID string
+	Key      string
+	Err      error
+}
+
+func (e *KeyMapError) Error() string {
+	if e.Key == "" {
+		return fmt.Sprintf("keymap: action %q: %v", e.ActionID, e.Err)
+	}
+	return fmt.Sprintf("keymap: action %q: key %q: %v", e.ActionID, e.Key, e.Err)
+}
+
+func (e *KeyMapError) Unwrap() error {
+	return e.Err
+}
+
+var ErrUnknownAction = errors.New("unknown action")
+
+// ParseKeyMapConfig parses a keymap written as JSON
+func ParseKeyMapConfig(data []byte) (KeyMapConfig, error) {
+	return ParseKeyMapConfigAs(data, KeyMapJSON)
+}
+
+// ParseKeyMapConfigAs parses a keymap written in format
+func ParseKeyMapConfigAs(data []byte, format KeyMapFormat) (KeyMapConfig, error) {
+	raw := map[string]any{}
+	var err error
+	switch format {
+	case KeyMapJSON:
+		err = json.Unmarshal(data, &raw)
+	case KeyMapTOML:
+		err = toml.Unmarshal(data, &raw)
+	case KeyMapYAML:
+		err = yaml.Unmarshal(data, &raw)
+	default:
+		err = fmt.Errorf("unknown format %d", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("keymap: %w", err)
+	}
+	config := KeyMapConfig{}
+	if err := config.addEntries("", raw); err != nil {
+		return nil, fmt.Errorf("keymap: %w", err)
+	}
+	return config, nil
+}
+
+// addEntries adds the decoded entries to the config, joining the
+// names of nested tables into dotted action IDs
+func (config KeyMapConfig) addEntries(prefix string, raw map[string]any) error {
+	for name, value := range raw {
+		actionID := prefix + name
+		switch value := value.(type) {
+		case map[string]any:
+			if err := config.addEntries(actionID+".", value); err != nil {
+				return err
+			}
+		case []any:
+			keys := make([]string, 0, len(value))
+			for _, key := range value {
+				keyString, ok := key.(string)
+				if !ok {
+					return fmt.Errorf("action %q: keys must be strings", actionID)
+				}
+				keys = append(keys, keyString)
+			}
+			config[actionID] = keys
+		case nil:
+			config[actionID] = []string{}
+		default:
+			return fmt.Errorf("action %q: expected a list of keys", actionID)
+		}
+	}
+	return nil
+}
+
+// LoadKeyMapConfig reads a keymap file written as JSON, TOML or YAML,
+// depending on its extension
+func LoadKeyMapConfig(path string) (KeyMapConfig, error) {
+	format, err := KeyMapFormatOf(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("keymap: %w", err)
+	}
+	return ParseKeyMapConfigAs(data, format)
+}
+
+type parsedKeys struct {
+	keyDefs      []KeyDef
+	keySequences [][]KeyDef
+}
+
+// parse converts the key strings of every action, collecting
+// an error for each key that cannot be parsed
+func (config KeyMapConfig) parse() (map[string]parsedKeys, []error) {
+	parsed := make(map[string]parsedKeys)
+	errs := []error{}
+	for _, actionID := range config.actionIDs() {
+		keys := parsedKeys{}
+		valid := true
+		for _, key := range config[actionID] {
+			sequence, err := ParseKeySequence(key)
+			if err != nil {
+				errs = append(errs, &KeyMapError{ActionID: actionID, Key: key, Err: err})
+				valid = false
+				continue
+			}
+			if len(sequence) == 1 {
+				keys.keyDefs = append(keys.keyDefs, sequence[0])
+			} else {
+				keys.keySequences = append(keys.keySequences, sequence)
+			}
+		}
+		if valid {
+			parsed[actionID] = keys
+		}
+	}
+	return parsed, errs
+}
+
+func (config KeyMapConfig) actionIDs() []string {
+	actionIDs := make([]string, 0, len(config))
+	for actionID := range config {
+		actionIDs = append(actionIDs, actionID)
+	}
+	sort.Strings(actionIDs)
+	return actionIDs
+}
+
+// ApplyToKeyBindings replaces the keys of every binding whose action ID
+// appears in the config. It returns the action IDs that were applied
+// along with the errors for keys that could not be parsed; actions
+// with unparsable keys are left untouched
+func (config KeyMapConfig) ApplyToKeyBindings(keyBindings []*KeyBinding) (map[string]bool, error) {
+	parsed, errs := config.parse()
+	applied := make(map[string]bool)
+	for _, keyBinding := range keyBindings {
+		keys, ok := parsed[keyBinding.ActionID]
+		if keyBinding.ActionID == "" || !ok {
+			continue
+		}
+		keyBinding.KeyDefs = keys.keyDefs
+		keyBinding.KeySequences = keys.keySequences
+		applied[keyBinding.ActionID] = true
+	}
+	return applied, errors.Join(errs...)
+}
+
+// ApplyKeyMapConfig applies the config to the key bindings of root
+// and all its descendants, including the bindings of their key layers.
+// Besides keys that cannot be parsed, it reports every action ID of
+// the config that no binding carries. The panels under root keep the
+// config and apply it to the key layers pushed and, for ListPanels,
+// the tabs added later
+func ApplyKeyMapConfig(root IPanel, config KeyMapConfig) error {
+	return applyKeyMapConfig(root, config)
+}
+
+// applyKeyMapConfig is ApplyKeyMapConfig with additional
+// key layer stacks, such as the global layers of the top level
+func applyKeyMapConfig(root IPanel, config KeyMapConfig, stacks ...*KeyLayerStack) error {
+	keyBindings := panelKeyBindings(root)
+	for _, stack := range stacks {
+		keyBindings = append(keyBindings, stack.allKeyBindings()...)
+		stack.keyMap = config
+	}
+	keepKeyMapConfig(root, config)
+
+	known := make(map[string]bool)
+	for _, keyBinding := range keyBindings {
+		known[keyBinding.ActionID] = true
+	}
+	_, err := config.ApplyToKeyBindings(keyBindings)
+	errs := []error{err}
+	for _, actionID := range config.actionIDs() {
+		if !known[actionID] {
+			errs = append(errs, &KeyMapError{ActionID: actionID, Err: ErrUnknownAction})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// panelKeyBindings returns the key bindings of root and all its
+// descendants, along with the bindings of their key layers
+func panelKeyBindings(root IPanel) []*KeyBinding {
+	keyBindings := []*KeyBinding{}
+	WalkPanels(root, func(panel IPanel) bool {
+		keyBindings = append(keyBindings, panel.GetKeyBindings()...)
+		if layered, ok := panel.(iPanelWithKeyLayerStack); ok {
+			keyBindings = append(keyBindings, layered.keyLayerStack().allKeyBindings()...)
+		}
+		return true
+	})
+	return keyBindings
+}
+
+func keepKeyMapConfig(root IPanel, config KeyMapConfig) {
+	WalkPanels(root, func(panel IPanel) bool {
+		if layered, ok := panel.(iPanelWithKeyLayerStack); ok {
+			layered.keyLayerStack().keyMap = config
+		}
+		return true
+	})
+}
@@ -0,0 +1,116 @@
+package peanutbutter
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestParseKeyMapConfigFormats(t *testing.T) {
+	tests := []struct {
+		format KeyMapFormat
+		data   string
+	}{
+		{KeyMapJSON, `{"editor": {"save": ["Ctrl+S"]}, "window.left": ["Ctrl+W h", "Alt+Left"], "quit": []}`},
+		{KeyMapTOML, "\"window.left\" = [\"Ctrl+W h\", \"Alt+Left\"]\nquit = []\n[editor]\nsave = [\"Ctrl+S\"]\n"},
+		{KeyMapYAML, "editor:\n  save: [Ctrl+S]\nwindow.left: [Ctrl+W h, Alt+Left]\nquit: []\n"},
+	}
+	for _, test := range tests {
+		config, err := ParseKeyMapConfigAs([]byte(test.data), test.format)
+		if err != nil {
+			t.Errorf("format %d: %v", test.format, err)
+			continue
+		}
+		if len(config) != 3 || len(config["editor.save"]) != 1 || len(config["window.left"]) != 2 || len(config["quit"]) != 0 {
+			t.Errorf("format %d: got %v", test.format, config)
+		}
+	}
+	if _, err := ParseKeyMapConfigAs([]byte(`{"save": "Ctrl+S"}`), KeyMapJSON); err == nil {
+		t.Error("expected an error for a key that is not in a list")
+	}
+}
+
+func TestLoadKeyMapConfigByExtension(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"keys.json": `{"save": ["Ctrl+S"]}`,
+		"keys.toml": `save = ["Ctrl+S"]`,
+		"keys.yml":  `save: [Ctrl+S]`,
+		"keys.yaml": `save: [Ctrl+S]`,
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		config, err := LoadKeyMapConfig(path)
+		if err != nil || len(config["save"]) != 1 {
+			t.Errorf("%s: got %v, %v", name, config, err)
+		}
+	}
+	if _, err := LoadKeyMapConfig(filepath.Join(dir, "keys.ini")); err == nil {
+		t.Error("expected an error for an unknown extension")
+	}
+}
+
+func TestApplyKeyMapConfig(t *testing.T) {
+	l := newTestLayout(t)
+	save := NewKeyBinding(WithActionID("save"), WithKeyDef(KeyDef{Key: tcell.KeyCtrlS, Modifiers: tcell.ModCtrl}), WithEnabled(true))
+	quit := NewKeyBinding(WithActionID("quit"), WithKeyDef(runeKeyDef('q')), WithEnabled(true))
+	l.a.AddKeyBinding(save)
+	l.d.AddKeyBinding(quit)
+
+	err := ApplyKeyMapConfig(l.top.ListPanel, KeyMapConfig{
+		"save":    {"Ctrl+W s", "Alt+s"},
+		"quit":    {"Hyper+q"},
+		"missing": {"x"},
+	})
+	if got := save.renderKeys(); got != "Alt+s/Ctrl+W s" {
+		t.Errorf("save bound to %q", got)
+	}
+	if got := quit.renderKeys(); got != "q" {
+		t.Errorf("quit with an unparsable key changed to %q", got)
+	}
+	var keyMapErr *KeyMapError
+	if !errors.As(err, &keyMapErr) {
+		t.Fatalf("got %v", err)
+	}
+	if !errors.Is(err, ErrUnknownAction) {
+		t.Error("missing action not reported")
+	}
+}
+
+func TestKeyMapConfigRemapsKeyLayers(t *testing.T) {
+	panelLayer := NewKeyBinding(WithActionID("insert.exit"), WithKeyDef(KeyDef{Key: tcell.KeyEsc}), WithEnabled(true))
+	l := newTestLayout(t, WithKeyMapConfig(KeyMapConfig{
+		"insert.exit": {"Ctrl+C"},
+		"resize.left": {"Left"},
+	}))
+	if err := l.top.KeyMapError(); !errors.Is(err, ErrUnknownAction) {
+		t.Errorf("got %v before the layers are pushed", err)
+	}
+
+	// layers pushed after Init are remapped as they are pushed
+	l.a.PushKeyLayer(NewKeyLayer("insert", false, panelLayer))
+	globalLayer := NewKeyBinding(WithActionID("resize.left"), WithKeyDef(runeKeyDef('h')), WithEnabled(true))
+	l.top.HandleMessage(PushGlobalKeyLayerMsg{Layer: NewKeyLayer("resize", false, globalLayer)})
+	if got := panelLayer.renderKeys(); got != "Ctrl+C" {
+		t.Errorf("panel layer bound to %q", got)
+	}
+	if got := globalLayer.renderKeys(); got != "Left" {
+		t.Errorf("global layer bound to %q", got)
+	}
+
+	// layers pushed before are remapped along with the panels
+	other := NewKeyBinding(WithActionID("insert.exit"), WithKeyDef(KeyDef{Key: tcell.KeyEsc}), WithEnabled(true))
+	l.b.PushKeyLayer(NewKeyLayer("insert", false, other))
+	if err := ApplyKeyMapConfig(l.b, KeyMapConfig{"insert.exit": {"Ctrl+G"}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := other.renderKeys(); got != "Ctrl+G" {
+		t.Errorf("layer pushed before bound to %q", got)
+	}
+}
@@ -0,0 +1,209 @@
+package peanutbutter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// KeyParseError is returned when a key string cannot be parsed
+type KeyParseError struct {
+	Input  string // the whole key string
+	Token  string // the part of the key string that is invalid
+	Reason string
+}
+
+func (e *KeyParseError) Error() string {
+	if e.Token == "" {
+		return fmt.Sprintf("invalid key %q: %s", e.Input, e.Reason)
+	}
+	return fmt.Sprintf("invalid key %q: %s %q", e.Input, e.Reason, e.Token)
+}
+
+var keyModifierNames = map[string]tcell.ModMask{
+	"shift":   tcell.ModShift,
+	"s":       tcell.ModShift,
+	"alt":     tcell.ModAlt,
+	"opt":     tcell.ModAlt,
+	"option":  tcell.ModAlt,
+	"a":       tcell.ModAlt,
+	"meta":    tcell.ModMeta,
+	"cmd":     tcell.ModMeta,
+	"command": tcell.ModMeta,
+	"super":   tcell.ModMeta,
+	"ctrl":    tcell.ModCtrl,
+	"ctl":     tcell.ModCtrl,
+	"control": tcell.ModCtrl,
+	"c":       tcell.ModCtrl,
+}
+
+// keyNameAliases are accepted in addition to the names in tcell.KeyNames
+var keyNameAliases = map[string]KeyDef{
+	"space":    {Key: tcell.KeyRune, Rune: ' '},
+	"plus":     {Key: tcell.KeyRune, Rune: '+'},
+	"escape":   {Key: tcell.KeyEsc},
+	"return":   {Key: tcell.KeyEnter},
+	"del":      {Key: tcell.KeyDelete},
+	"ins":      {Key: tcell.KeyInsert},
+	"pageup":   {Key: tcell.KeyPgUp},
+	"pagedown": {Key: tcell.KeyPgDn},
+}
+
+var keyNameLookup = func() map[string]tcell.Key {
+	lookup := make(map[string]tcell.Key, len(tcell.KeyNames))
+	for key, name := range tcell.KeyNames {
+		lookup[strings.ToLower(name)] = key
+	}
+	return lookup
+}()
+
+// splitKeyString splits a key string into its modifiers and key name
+// A trailing "+" is the plus key, e.g. "Ctrl++"
+func splitKeyString(s string) ([]string, string) {
+	if s == "+" {
+		return nil, "+"
+	}
+	if strings.HasSuffix(s, "++") {
+		return strings.Split(s[:len(s)-2], "+"), "+"
+	}
+	parts := strings.Split(s, "+")
+	return parts[:len(parts)-1], parts[len(parts)-1]
+}
+
+// ParseKeyDef parses a human readable key such as "Ctrl+Shift+Tab",
+// "alt+x", "F5" or "q". It accepts the key names of tcell.KeyNames
+// case-insensitively, single runes (case-sensitively), aliases such
+// as "Space", "Escape" or "PageUp", and the modifiers Shift, Alt,
+// Meta and Ctrl along with aliases such as "Control", "Opt" or "Cmd".
+// Ctrl with a letter is the matching control key, e.g. "ctrl+a" is
+// tcell.KeyCtrlA, which is what tcell reports for that key press.
+// Every KeyDef tcell can report parses back from its String()
+func ParseKeyDef(s string) (KeyDef, error) {
+	if s == "" {
+		return KeyDef{}, &KeyParseError{Input: s, Reason: "empty key"}
+	}
+	modifiers, name := splitKeyString(s)
+	keyDef := KeyDef{}
+	for _, modifier := range modifiers {
+		if modifier == "" {
+			return KeyDef{}, &KeyParseError{Input: s, Reason: "empty modifier"}
+		}
+		mod, ok := keyModifierNames[strings.ToLower(modifier)]
+		if !ok {
+			return KeyDef{}, &KeyParseError{Input: s, Token: modifier, Reason: "unknown modifier"}
+		}
+		if keyDef.Modifiers&mod != 0 {
+			return KeyDef{}, &KeyParseError{Input: s, Token: modifier, Reason: "repeated modifier"}
+		}
+		keyDef.Modifiers |= mod
+	}
+	if name == "" {
+		return KeyDef{}, &KeyParseError{Input: s, Reason: "missing key after modifiers"}
+	}
+
+	// KeyDef.String drops the "Ctrl-" of control keys when the
+	// Ctrl modifier is set, so "Ctrl+A" stands for Ctrl-A
+	if keyDef.Modifiers&tcell.ModCtrl != 0 {
+		if key, ok := keyNameLookup["ctrl-"+strings.ToLower(name)]; ok {
+			keyDef.Key = key
+			return keyDef, nil
+		}
+	}
+	if utf8.RuneCountInString(name) == 1 {
+		keyDef.Key = tcell.KeyRune
+		keyDef.Rune, _ = utf8.DecodeRuneInString(name)
+		return keyDef, nil
+	}
+	if key, ok := keyNameLookup[strings.ToLower(name)]; ok {
+		keyDef.Key = key
+		return keyDef, nil
+	}
+	if alias, ok := keyNameAliases[strings.ToLower(name)]; ok {
+		alias.Modifiers = keyDef.Modifiers
+		return alias, nil
+	}
+	if key, r, ok := parseRawKeyName(name); ok {
+		keyDef.Key = key
+		keyDef.Rune = r
+		return keyDef, nil
+	}
+	return KeyDef{}, &KeyParseError{Input: s, Token: name, Reason: "unknown key"}
+}
+
+// parseRawKeyName parses the "Key[key,rune]" form KeyDef.String
+// uses for keys that have no name
+func parseRawKeyName(name string) (tcell.Key, rune, bool) {
+	if !strings.HasPrefix(name, "Key[") || !strings.HasSuffix(name, "]") {
+		return 0, 0, false
+	}
+	key, r, found := strings.Cut(name[4:len(name)-1], ",")
+	if !found {
+		return 0, 0, false
+	}
+	k, err := strconv.Atoi(key)
+	if err != nil {
+		return 0, 0, false
+	}
+	ch, err := strconv.Atoi(r)
+	if err != nil {
+		return 0, 0, false
+	}
+	return tcell.Key(k), rune(ch), true
+}
+
+// MustParseKeyDef is like ParseKeyDef but panics if the key cannot be parsed
+// It is meant for keys written as literals in code
+func MustParseKeyDef(s string) KeyDef {
+	keyDef, err := ParseKeyDef(s)
+	if err != nil {
+		panic(err)
+	}
+	return keyDef
+}
+
+// ParseKeySequence parses space separated keys, e.g. "Ctrl+W h"
+// Use "Space" for the space key inside a sequence
+func ParseKeySequence(s string) ([]KeyDef, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		if s != "" {
+			keyDef, err := ParseKeyDef(s)
+			if err != nil {
+				return nil, err
+			}
+			return []KeyDef{keyDef}, nil
+		}
+		return nil, &KeyParseError{Input: s, Reason: "empty key sequence"}
+	}
+	keyDefs := make([]KeyDef, 0, len(fields))
+	for _, field := range fields {
+		keyDef, err := ParseKeyDef(field)
+		if err != nil {
+			return nil, err
+		}
+		keyDefs = append(keyDefs, keyDef)
+	}
+	return keyDefs, nil
+}
+
+// WithKeys adds keys written as human readable strings to the binding.
+// A string with several space separated keys adds a key sequence.
+// It panics if a key cannot be parsed
+func WithKeys(keys ...string) KeyBindingOption {
+	return func(keybinding *KeyBinding) {
+		for _, key := range keys {
+			sequence, err := ParseKeySequence(key)
+			if err != nil {
+				panic(err)
+			}
+			if len(sequence) == 1 {
+				keybinding.KeyDefs = append(keybinding.KeyDefs, sequence[0])
+			} else {
+				keybinding.KeySequences = append(keybinding.KeySequences, sequence)
+			}
+		}
+	}
+}
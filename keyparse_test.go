@@ -0,0 +1,78 @@
+package peanutbutter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestKeyDefStringRoundTrip(t *testing.T) {
+	modifiers := []tcell.ModMask{0, tcell.ModCtrl, tcell.ModAlt, tcell.ModShift, tcell.ModCtrl | tcell.ModShift | tcell.ModAlt | tcell.ModMeta}
+	for key := range tcell.KeyNames {
+		for _, mod := range modifiers {
+			keyDef := KeyDef{Key: key, Modifiers: mod}
+			if got, err := ParseKeyDef(keyDef.String()); err != nil || got != keyDef {
+				t.Errorf("%q: got %v, %v", keyDef.String(), got, err)
+			}
+		}
+	}
+	for _, r := range "aZ+-1~é" {
+		for _, mod := range []tcell.ModMask{0, tcell.ModAlt, tcell.ModShift} {
+			keyDef := KeyDef{Key: tcell.KeyRune, Rune: r, Modifiers: mod}
+			if got, err := ParseKeyDef(keyDef.String()); err != nil || got != keyDef {
+				t.Errorf("%q: got %v, %v", keyDef.String(), got, err)
+			}
+		}
+	}
+	unnamed := KeyDef{Key: tcell.Key(400), Rune: 3}
+	if got, err := ParseKeyDef(unnamed.String()); err != nil || got != unnamed {
+		t.Errorf("%q: got %v, %v", unnamed.String(), got, err)
+	}
+}
+
+func TestParseKeyDef(t *testing.T) {
+	tests := []struct {
+		in   string
+		want KeyDef
+	}{
+		{"q", KeyDef{Key: tcell.KeyRune, Rune: 'q'}},
+		{"Q", KeyDef{Key: tcell.KeyRune, Rune: 'Q'}},
+		{"ctrl+a", KeyDef{Key: tcell.KeyCtrlA, Modifiers: tcell.ModCtrl}},
+		{"Control+Shift+Tab", KeyDef{Key: tcell.KeyTab, Modifiers: tcell.ModCtrl | tcell.ModShift}},
+		{"opt+PageUp", KeyDef{Key: tcell.KeyPgUp, Modifiers: tcell.ModAlt}},
+		{"space", KeyDef{Key: tcell.KeyRune, Rune: ' '}},
+		{"Ctrl++", KeyDef{Key: tcell.KeyRune, Rune: '+', Modifiers: tcell.ModCtrl}},
+		{"F5", KeyDef{Key: tcell.KeyF5}},
+		{"Escape", KeyDef{Key: tcell.KeyEsc}},
+	}
+	for _, test := range tests {
+		got, err := ParseKeyDef(test.in)
+		if err != nil || got != test.want {
+			t.Errorf("%q: got %v, %v, want %v", test.in, got, err, test.want)
+		}
+	}
+}
+
+func TestParseKeyDefErrors(t *testing.T) {
+	for _, in := range []string{"", "Ctrl+", "Hyper+x", "ctrl+ctrl+x", "foo", "+x"} {
+		_, err := ParseKeyDef(in)
+		var parseErr *KeyParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("%q: got %v, want a KeyParseError", in, err)
+		}
+	}
+}
+
+func TestParseKeySequence(t *testing.T) {
+	sequence, err := ParseKeySequence("Ctrl+W Space g")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sequence) != 3 || RenderKeySequence(sequence) != "Ctrl+W Space g" {
+		t.Errorf("got %v", RenderKeySequence(sequence))
+	}
+	if _, err := ParseKeySequence("Ctrl+W nope"); err == nil {
+		t.Error("expected an error")
+	}
+}
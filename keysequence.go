@@ -0,0 +1,139 @@
+package peanutbutter
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gdamore/tcell/v2"
+)
+
+// DefaultKeySequenceTimeout is how long a partially typed
+// key sequence waits for its next key before it is cancelled
+var DefaultKeySequenceTimeout = time.Second
+
+// KeySequenceState tracks the keys typed so far of a multi-key
+// binding such as "Ctrl+W h". The top level panel owns one and
+// attaches it to every KeyMsg, so KeyBindingsHandler can match
+// sequences across key-strokes
+type KeySequenceState struct {
+	Pending    []KeyDef
+	Timeout    time.Duration
+	lastKey    time.Time
+	generation int
+	completed  bool // set during dispatch when a sequence fired
+	extended   bool // set during dispatch when the key continues a sequence
+}
+
+// KeySequencePendingMsg is broadcast whenever the pending key
+// sequence changes. Pending is empty once the sequence completes
+// or is cancelled
+type KeySequencePendingMsg struct {
+	Pending []KeyDef
+}
+
+type keySequenceTimeoutMsg struct {
+	generation int
+}
+
+func (s *KeySequenceState) IsPending() bool {
+	return s != nil && len(s.Pending) > 0
+}
+
+func (s *KeySequenceState) getTimeout() time.Duration {
+	if s.Timeout > 0 {
+		return s.Timeout
+	}
+	return DefaultKeySequenceTimeout
+}
+
+func (s *KeySequenceState) isExpired(now time.Time) bool {
+	return s.IsPending() && now.Sub(s.lastKey) > s.getTimeout()
+}
+
+func (s *KeySequenceState) beginKey() {
+	s.completed = false
+	s.extended = false
+}
+
+func (s *KeySequenceState) reset() {
+	s.Pending = nil
+	s.generation++
+}
+
+// finishKey updates the pending keys after a key-stroke has been
+// dispatched. It returns true if the pending keys changed
+func (s *KeySequenceState) finishKey(keyDef KeyDef, now time.Time) bool {
+	switch {
+	case s.completed:
+		wasPending := s.IsPending()
+		s.reset()
+		return wasPending
+	case s.extended:
+		s.Pending = append(s.Pending, keyDef)
+		s.lastKey = now
+		s.generation++
+		return true
+	case s.IsPending():
+		// a key that does not continue any sequence cancels it
+		s.reset()
+		return true
+	}
+	return false
+}
+
+func (s *KeySequenceState) timeoutCmd() tea.Cmd {
+	generation := s.generation
+	return tea.Tick(s.getTimeout(), func(time.Time) tea.Msg {
+		return keySequenceTimeoutMsg{generation: generation}
+	})
+}
+
+type sequenceMatch int
+
+const (
+	noSequenceMatch sequenceMatch = iota
+	sequencePrefixMatch
+	sequenceCompleteMatch
+)
+
+// matchSequence checks if the pending keys followed by keyDef
+// complete or continue one of the key sequences of the binding
+func (keybinding *KeyBinding) matchSequence(pending []KeyDef, keyDef KeyDef) sequenceMatch {
+	result := noSequenceMatch
+	for _, sequence := range keybinding.KeySequences {
+		if len(sequence) <= len(pending) {
+			continue
+		}
+		matches := sequence[len(pending)].matchesKeyDef(keyDef)
+		for i := 0; matches && i < len(pending); i++ {
+			matches = sequence[i].matchesKeyDef(pending[i])
+		}
+		if !matches {
+			continue
+		}
+		if len(sequence) == len(pending)+1 {
+			return sequenceCompleteMatch
+		}
+		result = sequencePrefixMatch
+	}
+	return result
+}
+
+func isEscapeKey(eventKey *tcell.EventKey) bool {
+	return eventKey.Key() == tcell.KeyEsc && eventKey.Modifiers() == 0
+}
+
+// RenderKeySequence renders a key sequence as space separated keys
+// The space key is rendered as "Space", so ParseKeySequence can read it back
+func RenderKeySequence(keyDefs []KeyDef) string {
+	keys := []string{}
+	for _, keyDef := range keyDefs {
+		key := keyDef.String()
+		if keyDef.Key == tcell.KeyRune && keyDef.Rune == ' ' {
+			key = strings.TrimSuffix(key, " ") + "Space"
+		}
+		keys = append(keys, key)
+	}
+	return strings.Join(keys, " ")
+}
@@ -0,0 +1,108 @@
+package peanutbutter
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gdamore/tcell/v2"
+)
+
+// sequenceLayout focuses a, which has Override bindings for the key
+// sequences "Ctrl+W h" and "g g". Keys are sent without running the
+// commands, which would wait for the key sequence timeout
+func sequenceLayout(t *testing.T, fired *string) *testLayout {
+	l := newTestLayout(t)
+	ctrlW := KeyDef{Key: tcell.KeyCtrlW, Modifiers: tcell.ModCtrl}
+	g := KeyDef{Key: tcell.KeyRune, Rune: 'g'}
+	h := KeyDef{Key: tcell.KeyRune, Rune: 'h'}
+	for _, sequence := range [][]KeyDef{{ctrlW, h}, {g, g}} {
+		name := RenderKeySequence(sequence)
+		keyBinding := NewKeyBinding(
+			WithKeySequence(sequence...),
+			WithEnabled(true),
+			WithFunc(func() tea.Cmd {
+				*fired += name + ";"
+				return nil
+			}),
+		)
+		keyBinding.Override = true
+		l.a.AddKeyBinding(keyBinding)
+	}
+	l.focus(l.a)
+	return l
+}
+
+func TestKeySequenceFires(t *testing.T) {
+	fired := ""
+	l := sequenceLayout(t, &fired)
+	l.top.HandleMessage(testKeyMsg(tcell.KeyCtrlW, 0, tcell.ModCtrl))
+	if pending := RenderKeySequence(l.top.PendingKeySequence()); pending != "Ctrl+W" {
+		t.Errorf("pending %q", pending)
+	}
+	l.top.HandleMessage(testKeyMsg(tcell.KeyRune, 'h', 0))
+	l.top.HandleMessage(testKeyMsg(tcell.KeyRune, 'g', 0))
+	l.top.HandleMessage(testKeyMsg(tcell.KeyRune, 'g', 0))
+	if fired != "Ctrl+W h;g g;" {
+		t.Errorf("fired %q", fired)
+	}
+	if len(l.top.PendingKeySequence()) != 0 {
+		t.Error("sequence still pending")
+	}
+	if keyMsgs := l.leaf(l.a).keyMsgs(); len(keyMsgs) != 0 {
+		t.Errorf("leaf got %d keys of the sequences", len(keyMsgs))
+	}
+}
+
+func TestKeySequenceCancelledByOtherKey(t *testing.T) {
+	fired := ""
+	l := sequenceLayout(t, &fired)
+	l.top.HandleMessage(testKeyMsg(tcell.KeyRune, 'g', 0))
+	l.top.HandleMessage(testKeyMsg(tcell.KeyRune, 'x', 0))
+	l.top.HandleMessage(testKeyMsg(tcell.KeyRune, 'g', 0))
+	if fired != "" {
+		t.Errorf("fired %q", fired)
+	}
+	// the cancelling key is dropped along with the sequence
+	if keyMsgs := l.leaf(l.a).keyMsgs(); len(keyMsgs) != 0 {
+		t.Errorf("leaf got %d keys", len(keyMsgs))
+	}
+	l.top.HandleMessage(testKeyMsg(tcell.KeyEsc, 0, 0))
+	l.top.HandleMessage(testKeyMsg(tcell.KeyRune, 'g', 0))
+	if fired != "" || len(l.top.PendingKeySequence()) != 1 {
+		t.Errorf("fired %q, pending %v after Esc", fired, l.top.PendingKeySequence())
+	}
+}
+
+func TestKeySequenceTimeout(t *testing.T) {
+	fired := ""
+	l := sequenceLayout(t, &fired)
+	l.top.keySequence.Timeout = time.Millisecond
+	l.top.HandleMessage(testKeyMsg(tcell.KeyCtrlW, 0, tcell.ModCtrl))
+	l.pump()
+	if len(l.top.PendingKeySequence()) != 0 {
+		t.Fatal("sequence still pending after the timeout")
+	}
+	l.top.HandleMessage(testKeyMsg(tcell.KeyRune, 'h', 0))
+	if fired != "" {
+		t.Errorf("fired %q", fired)
+	}
+}
+
+// TestKeySequencePendingMsg checks that every panel is told about
+// the pending keys, not only the focused one
+func TestKeySequencePendingMsg(t *testing.T) {
+	fired := ""
+	l := sequenceLayout(t, &fired)
+	l.top.HandleMessage(testKeyMsg(tcell.KeyCtrlW, 0, tcell.ModCtrl))
+	l.top.HandleMessage(testKeyMsg(tcell.KeyRune, 'h', 0))
+	pending := []string{}
+	for _, msg := range l.leaf(l.b).msgs {
+		if pendingMsg, ok := msg.(KeySequencePendingMsg); ok {
+			pending = append(pending, RenderKeySequence(pendingMsg.Pending))
+		}
+	}
+	if len(pending) != 2 || pending[0] != "Ctrl+W" || pending[1] != "" {
+		t.Errorf("got %q", pending)
+	}
+}
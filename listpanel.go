@@ -5,28 +5,37 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	tcellviews "github.com/gdamore/tcell/v2/views"
-	"github.com/mattn/go-runewidth"
 )
 
 // ListPanel can house a list of panels
 // that can be displayed in a horizontal, vertical, or stacked layout
 // List panels also support handling focus propagation
 type ListPanel struct {
-	Panels       []IPanel
-	path         []int // Path to uniquely identify this node in the hierarchy
-	MsgForParent tea.Msg
-	Layout       Layout
-	Selected     int // Index of the selected panel, only used if the orientation is ZStacked
-	Name         string
-	view         *tcellviews.ViewPort
-	redraw       bool
-	cmds         chan tea.Cmd
-	tabHidden    bool
-	topLevel     bool
-	panelStyle   PanelStyle
-	titleStyle   TitleStyle
-	iAmInFocus   bool
-	KeyBindings  []*KeyBinding
+	Panels          []IPanel
+	path            []int // Path to uniquely identify this node in the hierarchy
+	MsgForParent    tea.Msg
+	Layout          Layout
+	Selected        int // Index of the selected panel, only used if the orientation is ZStacked
+	Name            string
+	view            *tcellviews.ViewPort
+	redraw          bool
+	cmds            chan tea.Cmd
+	tabHidden       bool
+	topLevel        bool
+	panelStyle      PanelStyle
+	titleStyle      TitleStyle
+	iAmInFocus      bool
+	KeyBindings     []*KeyBinding
+	tabFocus        map[IPanel]IPanel // last focused descendant of each tab, only used if ZStacked
+	tabBarPosition  TabBarPosition
+	tabBarAlignment TabBarAlignment
+	tabBar          string // what the tab bar showed when last drawn
+	numberedTabs    *numberedTabKeys
+	initialFocus    *initialFocus
+	title           string
+	focusWithin     bool
+	borderState     focusState // what the border showed when last drawn
+	KeyLayerStack
 }
 
 var _ IPanel = &ListPanel{}
@@ -41,7 +50,7 @@ func (p *ListPanel) SetView(view *tcellviews.ViewPort) {
 
 func (p *ListPanel) renderBorder() {
 	renderBorder(
-		p.IAmInFocus(),
+		p.focusState() != unfocused,
 		p.panelStyle,
 		p.view,
 	)
@@ -59,9 +68,13 @@ func (p *ListPanel) AddKeyBinding(kb *KeyBinding) {
 	p.KeyBindings = append(p.KeyBindings, kb)
 }
 
+func (p *ListPanel) GetKeyBindings() []*KeyBinding {
+	return p.KeyBindings
+}
+
 func (p *ListPanel) HandleKeybindings(msg KeyMsg, onlyOverrides bool) tea.Cmd {
 	DebugPrintf("ListPanel received message from child: %T %+v\n", msg, msg)
-	return KeyBindingsHandler(p.KeyBindings, msg, onlyOverrides)
+	return KeyBindingsHandler(p.ActiveKeyBindings(p.KeyBindings), msg, onlyOverrides)
 }
 
 func (p *ListPanel) Draw(force bool) bool {
@@ -73,7 +86,9 @@ func (p *ListPanel) Draw(force bool) bool {
 	}
 
 	if p.Layout.Orientation == ZStacked {
-		redrawn = p.Panels[p.Selected].Draw(continueForce)
+		if len(p.Panels) > 0 {
+			redrawn = p.Panels[p.Selected].Draw(continueForce)
+		}
 	} else {
 		for _, panel := range p.Panels {
 			panelDrawn := panel.Draw(continueForce)
@@ -83,40 +98,27 @@ func (p *ListPanel) Draw(force bool) bool {
 		}
 	}
 
-	if redrawn || continueForce {
+	tabBarChanged := false
+	if p.Layout.Orientation == ZStacked {
+		tabBar := p.tabBarSignature()
+		tabBarChanged = tabBar != p.tabBar
+		p.tabBar = tabBar
+	}
+	borderState := p.focusState()
+	borderChanged := borderState != p.borderState
+	p.borderState = borderState
+	if redrawn || continueForce || tabBarChanged || borderChanged {
 		p.renderBorder()
 		if p.Layout.Orientation == ZStacked {
 			p.renderTabs()
+		} else {
+			p.renderTitle()
 		}
 	}
 	p.redraw = false
 	return redrawn
 }
 
-func (p *ListPanel) renderTabs() {
-	s := titleOffset
-	for i, panel := range p.Panels {
-		selected := i == p.Selected
-		txt := panel.GetName()
-		if selected {
-			txt = "[" + txt + "]"
-		} else {
-			s += 1
-		}
-		renderTextOnBorder(
-			p.titleStyle.RenderTitle(txt, selected),
-			renderOnTopEdge,
-			offsetFromLeftSide,
-			s,
-			p.view,
-		)
-		s += runewidth.StringWidth(txt)
-		if !selected {
-			s += 1
-		}
-	}
-}
-
 func NewListPanel(models []IPanel, layout Layout, options ...ListPanelOption) *ListPanel {
 	panels := make([]IPanel, len(models))
 
@@ -173,8 +175,7 @@ func WithTabAdvanceKeyBindings(keyBindings KeyBinding) ListPanelOption {
 	newKb := keyBindings
 	return func(m *ListPanel) {
 		newKb.Func = func() tea.Cmd {
-			m.TabNext()
-			return nil
+			return m.TabNext()
 		}
 		m.AddKeyBinding(&newKb)
 	}
@@ -184,8 +185,7 @@ func WithTabReverseKeyBindings(keyBindings KeyBinding) ListPanelOption {
 	newKb := keyBindings
 	return func(m *ListPanel) {
 		newKb.Func = func() tea.Cmd {
-			m.TabPrev()
-			return nil
+			return m.TabPrev()
 		}
 		m.AddKeyBinding(&newKb)
 	}
@@ -198,6 +198,7 @@ func (m *ListPanel) Init(cmds chan tea.Cmd) {
 	}
 
 	DebugPrintf("ListPanel.Init() called for %v\n", m.path)
+	m.updateTabHidden()
 	for _, panel := range m.Panels {
 		panel.Init(cmds)
 	}
@@ -216,8 +217,15 @@ func (m *ListPanel) SetPath(path []int) {
 	}
 }
 
+func (m *ListPanel) GetChildren() []IPanel {
+	return m.Panels
+}
+
 func (m *ListPanel) IsFocused() bool {
-	// A ListPanel is focused if any of its children are focused
+	// A ListPanel is focused if it or any of its children are focused
+	if m.iAmInFocus {
+		return true
+	}
 	for _, panel := range m.Panels {
 		if panel.IsFocused() {
 			return true
@@ -271,6 +279,9 @@ func (m *ListPanel) HandleMessage(msg Msg) {
 	case ResizeMsg:
 		m.HandleSizeMsg(msg)
 
+	case MouseMsg:
+		m.HandleMouseMsg(msg)
+
 	case FocusPropagatedMsgType:
 		if keyMsg, ok := msg.Msg.(KeyMsg); ok {
 			if m.iAmInFocus {
@@ -303,17 +314,29 @@ func (m *ListPanel) HandleMessage(msg Msg) {
 		r_path := msg.GetRoutePath().Path
 		l_msgpath := len(r_path)
 		if l_mypath == l_msgpath {
+			if globalMsg, ok := msg.Msg.(ConsiderForGlobalShortcutMsg); ok {
+				if cmd := GlobalKeyBindingsHandler(m.ActiveKeyBindings(m.KeyBindings), globalMsg.KeyMsg); cmd != nil {
+					m.cmds <- cmd
+				}
+				return
+			}
 			m.HandleMyOwnFocus(msg.Msg)
 			return
 		} else {
 			nextIdx := r_path[l_mypath]
-			if nextIdx < 0 || nextIdx > len(m.Panels) {
+			if nextIdx < 0 || nextIdx >= len(m.Panels) {
 				return
 			}
+			if _, ok := msg.Msg.(FocusGrantMsg); ok {
+				m.rememberTabFocus(m.Panels[nextIdx], r_path)
+			}
 			m.Panels[nextIdx].HandleMessage(msg.Msg)
 		}
 
 	case BroadcastMsgType:
+		if selectMsg, ok := msg.Msg.(SelectTabIndexMsg); ok && m.Layout.Orientation == ZStacked && selectMsg.ListPanelName == m.Name {
+			m.cmds <- m.SelectTab(selectMsg)
+		}
 		m.HandleMyOwnFocus(msg.Msg)
 		for _, panel := range m.Panels {
 			//DebugPrintf("ListPanel %v broadcasting message to child %v\n", m.path, i)
@@ -323,6 +346,26 @@ func (m *ListPanel) HandleMessage(msg Msg) {
 	}
 }
 
+// HandleMouseMsg forwards the mouse message to the visible child
+// whose view contains the point, converting the coordinates to
+// be relative to the child's view
+func (m *ListPanel) HandleMouseMsg(msg MouseMsg) {
+	for i, panel := range m.Panels {
+		if m.Layout.Orientation == ZStacked && i != m.Selected {
+			continue
+		}
+		if panel.IsInHiddenTab() {
+			continue
+		}
+		x, y, inside := viewContains(panel.GetView(), msg.X, msg.Y)
+		if inside {
+			DebugPrintf("ListPanel %v forwarding mouse message to %v\n", m.path, panel.GetPath())
+			panel.HandleMessage(MouseMsg{EventMouse: msg.EventMouse, X: x, Y: y})
+			return
+		}
+	}
+}
+
 func (m *ListPanel) GetFocusIndex() int {
 	for i, panel := range m.Panels {
 		if panel.IsFocused() {
@@ -332,29 +375,17 @@ func (m *ListPanel) GetFocusIndex() int {
 	return -1
 }
 
-func (m *ListPanel) handleFocusIndex(direction Relation) int {
-	focusIndex := m.GetFocusIndex()
-	len := len(m.Panels)
-	if direction == Up {
-		focusIndex--
-	}
-	if direction == Down {
-		focusIndex++
-	}
-	if direction == Left {
-		focusIndex--
-	}
-	if direction == Right {
-		focusIndex++
-	}
-
-	return ((focusIndex % len) + len) % len
-}
-
+// HandleFocusRequestMsg returns the focus grant for the panel
+// next to the requested one in the direction of msg.Relation
+//
+// Deprecated: the top level panel moves the focus spatially across
+// the whole layout; use FindPanelInDirection instead
 func (m *ListPanel) HandleFocusRequestMsg(msg FocusRequestMsg) *FocusGrantMsg {
-	newFocusIndex := m.handleFocusIndex(msg.Relation)
-	m.SetSelected(newFocusIndex)
-	return &FocusGrantMsg{RoutePath: RoutePath{Path: m.GetPath()}, Relation: msg.Relation}
+	target := FindPanelInDirection(m, msg.RequestedPath, msg.Relation)
+	if target == nil {
+		return nil
+	}
+	return &FocusGrantMsg{RoutePath: RoutePath{Path: target.GetPath()}, Relation: msg.Relation}
 }
 
 func (m ListPanel) GetLayout() Layout {
@@ -448,18 +479,100 @@ func (m *ListPanel) setSelectedModel(model IPanel) {
 	m.Panels[m.Selected] = model
 }
 
+// SetSelected selects the i-th tab. The returned command
+// broadcasts a SelectedTabIndexMsg if the selection changed.
+// If the focus was inside the previous tab, it also moves the
+// focus into the new tab, see restoreTabFocusCmd
 func (m *ListPanel) SetSelected(i int) tea.Cmd {
 	DebugPrintf("ListPanel %v setting selected to %v\n", m.path, i)
+	changed := m.Selected != i
+	focusInTab := !m.iAmInFocus && m.IsFocused()
 	m.Selected = i
 	m.redraw = true
+	m.updateTabHidden()
+	if !changed {
+		return nil
+	}
+	selectedMsg := SelectedTabIndexMsg{Index: i, TabName: m.Panels[i].GetName(), ListPanelName: m.Name}
+	cmds := []tea.Cmd{func() tea.Msg {
+		return selectedMsg
+	}}
+	if focusInTab && m.Layout.Orientation == ZStacked {
+		cmds = append(cmds, m.restoreTabFocusCmd(i))
+	}
+	return tea.Batch(cmds...)
+}
+
+// rememberTabFocus records the panel at path as the last
+// focused descendant of tab
+func (m *ListPanel) rememberTabFocus(tab IPanel, path []int) {
+	if m.Layout.Orientation != ZStacked {
+		return
+	}
+	if m.tabFocus == nil {
+		m.tabFocus = make(map[IPanel]IPanel)
+	}
+	if focused := FindPanelByPath(tab, path); focused != nil {
+		m.tabFocus[tab] = focused
+	}
+}
+
+// restoreTabFocusCmd requests focus for the last focused descendant
+// of the i-th tab, or its first visible leaf if it has none
+func (m *ListPanel) restoreTabFocusCmd(i int) tea.Cmd {
+	tab := m.Panels[i]
+	var target IPanel
+	if focused, ok := m.tabFocus[tab]; ok && FindPanelByPath(tab, focused.GetPath()) == focused && !focused.IsInHiddenTab() {
+		target = focused
+	}
+	if target == nil {
+		for _, leaf := range LeafPanels(tab) {
+			if !leaf.IsInHiddenTab() {
+				target = leaf
+				break
+			}
+		}
+	}
+	if target == nil {
+		return nil
+	}
+	focusRequestMsg := FocusRequestMsg{RequestedPath: target.GetPath(), Relation: Self}
+	return func() tea.Msg {
+		return focusRequestMsg
+	}
+}
+
+// TabIndex returns the index of the tab called name, or -1
+func (m *ListPanel) TabIndex(name string) int {
 	for i, panel := range m.Panels {
-		if i == m.Selected {
-			panel.SetTabHidden(false)
-		} else {
-			panel.SetTabHidden(true)
+		if panel.GetName() == name {
+			return i
 		}
 	}
-	return nil
+	return -1
+}
+
+// SelectTab selects the tab given by msg, by TabName if it is
+// set and by Index otherwise. Unknown tabs are ignored
+func (m *ListPanel) SelectTab(msg SelectTabIndexMsg) tea.Cmd {
+	i := msg.Index
+	if msg.TabName != "" {
+		i = m.TabIndex(msg.TabName)
+	}
+	if i < 0 || i >= len(m.Panels) {
+		DebugPrintf("ListPanel %v cannot select tab %+v\n", m.path, msg)
+		return nil
+	}
+	return m.SetSelected(i)
+}
+
+// updateTabHidden hides every child that is not the selected tab
+// of a ZStacked list, or all children if this panel is itself hidden
+func (m *ListPanel) updateTabHidden() {
+	for i, panel := range m.Panels {
+		hidden := m.tabHidden || (m.Layout.Orientation == ZStacked && i != m.Selected)
+		panel.SetTabHidden(hidden)
+	}
 }
 
 func (m *ListPanel) GetName() string {
@@ -467,18 +580,22 @@ func (m *ListPanel) GetName() string {
 }
 
 func (m *ListPanel) TabNext() tea.Cmd {
+	if len(m.Panels) == 0 {
+		return nil
+	}
 	return m.SetSelected((m.Selected + 1) % len(m.Panels))
 }
 
 func (m *ListPanel) TabPrev() tea.Cmd {
+	if len(m.Panels) == 0 {
+		return nil
+	}
 	return m.SetSelected((m.Selected - 1 + len(m.Panels)) % len(m.Panels))
 }
 
 func (m *ListPanel) SetTabHidden(hidden bool) {
 	m.tabHidden = hidden
-	for _, panel := range m.Panels {
-		panel.SetTabHidden(hidden)
-	}
+	m.updateTabHidden()
 }
 
 func (m *ListPanel) IsInHiddenTab() bool {
@@ -0,0 +1,72 @@
+package peanutbutter
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gdamore/tcell/v2"
+)
+
+func testMouseMsg(x, y int, buttons tcell.ButtonMask) MouseMsg {
+	return MouseMsg{EventMouse: tcell.NewEventMouse(x, y, buttons, 0), X: x, Y: y}
+}
+
+func lastMouseMsg(leaf *testLeaf) (MouseMsg, bool) {
+	for i := len(leaf.msgs) - 1; i >= 0; i-- {
+		if mouseMsg, ok := leaf.msgs[i].(MouseMsg); ok {
+			return mouseMsg, true
+		}
+	}
+	return MouseMsg{}, false
+}
+
+func TestMouseClickFocusesPanelUnderPointer(t *testing.T) {
+	l := newTestLayout(t)
+	l.top.HandleMessage(testMouseMsg(10, 5, tcell.ButtonPrimary))
+	l.pump()
+	if l.focused() != l.a {
+		t.Fatalf("focused %v, want a", l.focused())
+	}
+	mouseMsg, ok := lastMouseMsg(l.leaf(l.a))
+	if !ok {
+		t.Fatal("a's model got no mouse message")
+	}
+	// a's model view starts inside its border
+	if mouseMsg.X != 9 || mouseMsg.Y != 4 {
+		t.Errorf("model got %v,%v, want 9,4", mouseMsg.X, mouseMsg.Y)
+	}
+}
+
+func TestMouseSkipsHiddenTabs(t *testing.T) {
+	l := newTestLayout(t)
+	l.top.HandleMessage(testMouseMsg(60, 18, tcell.ButtonPrimary))
+	l.pump()
+	if l.focused() != l.c {
+		t.Fatalf("focused %v, want the visible tab c", l.focused())
+	}
+	if _, ok := lastMouseMsg(l.leaf(l.d)); ok {
+		t.Error("hidden tab d got a mouse message")
+	}
+}
+
+func TestMouseMoveDoesNotFocus(t *testing.T) {
+	l := newTestLayout(t)
+	l.top.HandleMessage(testMouseMsg(10, 5, tcell.ButtonNone))
+	l.pump()
+	if l.focused() != nil {
+		t.Fatalf("focused %v after a mouse move", l.focused())
+	}
+	if _, ok := lastMouseMsg(l.leaf(l.a)); !ok {
+		t.Error("a's model got no mouse message")
+	}
+}
+
+func TestMapMouseMsg(t *testing.T) {
+	mouse := MapMouseMsg(testMouseMsg(3, 4, tcell.ButtonPrimary))
+	if mouse.X != 3 || mouse.Y != 4 || mouse.Button != tea.MouseButtonLeft {
+		t.Errorf("got %+v", mouse)
+	}
+	if release := MapMouseMsg(testMouseMsg(3, 4, tcell.ButtonNone)); release.Action != tea.MouseActionRelease {
+		t.Errorf("got %+v, want a release", release)
+	}
+}
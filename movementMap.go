@@ -16,9 +16,29 @@ type IMovementNode interface {
 	Last() IPanel
 }
 
+// IsPanelFocusable returns true if focus movement may land on
+// the panel: it is not in a hidden tab, not disabled and has a size
+func IsPanelFocusable(panel IPanel) bool {
+	if panel == nil || panel.IsInHiddenTab() {
+		return false
+	}
+	if enabled, ok := panel.(IPanelWithEnabled); ok && !enabled.IsEnabled() {
+		return false
+	}
+	view := panel.GetView()
+	if view == nil {
+		return false
+	}
+	width, height := view.Size()
+	return width > 0 && height > 0
+}
+
+// PanelSequence visits its panels in order, skipping panels that
+// are not focusable at the time of the movement
 type PanelSequence struct {
 	panelList     []IPanel
 	panelPosition map[IPanel]int
+	loopAround    bool
 }
 
 var _ IMovementNode = &PanelSequence{}
@@ -34,6 +54,14 @@ func NewPanelSequence(panels ...IPanel) *PanelSequence {
 	}
 }
 
+// NewLoopAroundPanelSequence is a PanelSequence that continues
+// with its first panel after the last one and vice versa
+func NewLoopAroundPanelSequence(panels ...IPanel) *PanelSequence {
+	m := NewPanelSequence(panels...)
+	m.loopAround = true
+	return m
+}
+
 func (m *PanelSequence) IsListOfPanels() bool {
 	return true
 }
@@ -73,33 +101,99 @@ func (m *PanelSequence) keybindingHelper(panel IPanel, kb KeyBinding, fn func(IP
 	panel.AddKeyBinding(&newKb)
 }
 
+// AddMovementKeyBindings adds next and previous bindings to container
+// that move the focus through root. The target is worked out when the
+// key is pressed, from the most deeply nested focused panel under
+// container, so panels that became hidden, disabled or empty are skipped.
+// If the focused panel is not part of root, the focus goes to its
+// first or last panel
+func AddMovementKeyBindings(root IMovementNode, container IPanel, next KeyBinding, prev KeyBinding) {
+	nextKb, prevKb := next, prev
+	nextKb.Func = movementFunc(container, root.Next)
+	prevKb.Func = movementFunc(container, root.Previous)
+	container.AddKeyBinding(&nextKb)
+	container.AddKeyBinding(&prevKb)
+}
+
+func movementFunc(container IPanel, fn func(IPanel) IPanel) func() tea.Cmd {
+	return func() tea.Cmd {
+		var focused IPanel
+		if focusPath := FocusPath(container); len(focusPath) > 0 {
+			focused = focusPath[len(focusPath)-1]
+		}
+		target := fn(focused)
+		if target == nil {
+			return nil
+		}
+		return func() tea.Msg {
+			return FocusRequestMsg{
+				RequestedPath: target.GetPath(),
+				Relation:      Self,
+			}
+		}
+	}
+}
+
 func (m *PanelSequence) contains(panel IPanel) bool {
 	_, ok := m.panelPosition[panel]
 	return ok
 }
 
+// step returns the first focusable panel after index in the given
+// direction, wrapping around if the sequence loops
+func (m *PanelSequence) step(index int, direction int) IPanel {
+	n := len(m.panelList)
+	for i := 1; i <= n; i++ {
+		next := index + direction*i
+		if next < 0 || next >= n {
+			if !m.loopAround {
+				return nil
+			}
+			next = ((next % n) + n) % n
+		}
+		if IsPanelFocusable(m.panelList[next]) {
+			return m.panelList[next]
+		}
+	}
+	return nil
+}
+
+// Next returns the next focusable panel, or the first one
+// if panel is not part of the sequence
 func (m *PanelSequence) Next(panel IPanel) IPanel {
-	index := m.panelPosition[panel]
-	if index == len(m.panelList)-1 {
-		return nil
+	index, ok := m.panelPosition[panel]
+	if !ok {
+		return m.First()
 	}
-	return m.panelList[index+1]
+	return m.step(index, 1)
 }
 
+// Previous returns the previous focusable panel, or the last one
+// if panel is not part of the sequence
 func (m *PanelSequence) Previous(panel IPanel) IPanel {
-	index := m.panelPosition[panel]
-	if index == 0 {
-		return nil
+	index, ok := m.panelPosition[panel]
+	if !ok {
+		return m.Last()
 	}
-	return m.panelList[index-1]
+	return m.step(index, -1)
 }
 
 func (m *PanelSequence) First() IPanel {
-	return m.panelList[0]
+	for _, panel := range m.panelList {
+		if IsPanelFocusable(panel) {
+			return panel
+		}
+	}
+	return nil
 }
 
 func (m *PanelSequence) Last() IPanel {
-	return m.panelList[len(m.panelList)-1]
+	for i := len(m.panelList) - 1; i >= 0; i-- {
+		if IsPanelFocusable(m.panelList[i]) {
+			return m.panelList[i]
+		}
+	}
+	return nil
 }
 
 func (m *PanelSequence) allPanels() []IPanel {
@@ -124,6 +218,14 @@ func NewLoopAroundTabMap(chains ...IMovementNode) *SelectorNode {
 	return k
 }
 
+// NewSequentialSelectorNode visits its chains one after the other
+// without looping around
+func NewSequentialSelectorNode(chains ...IMovementNode) *SelectorNode {
+	k := NewVisibleSelectorNode(chains...)
+	k.sequential = true
+	return k
+}
+
 func NewVisibleSelectorNode(chains ...IMovementNode) *SelectorNode {
 	chainMap := make(map[IPanel]IMovementNode)
 	chainPosition := make(map[IPanel]int)
@@ -160,72 +262,87 @@ func (m *SelectorNode) CreateKeyBindings(root IMovementNode, kb func(IPanel) Key
 	}
 }
 
+// Next returns the next focusable panel of the chain of panel. In
+// sequential mode it then continues with the following chains that
+// have a focusable panel, looping around if configured to
 func (m *SelectorNode) Next(panel IPanel) IPanel {
-	chain := m.chainMap[panel]
+	chain, ok := m.chainMap[panel]
+	if !ok {
+		return m.First()
+	}
+	if next := chain.Next(panel); next != nil {
+		return next
+	}
+	if !m.sequential {
+		return nil // only sequential selectors move on to other chains
+	}
 	chainIndex := m.chainPosition[panel]
-	next := chain.Next(panel)
-	if next == nil {
-		// if next is nil, that means it is the last panel in the chain
-		if !m.sequential {
-			return nil // in sequential mode, we don't look at other chains
-		}
-		if chainIndex == len(m.chains)-1 {
-			if m.loopAround {
-				return m.First()
+	for i := 1; i <= len(m.chains); i++ {
+		nextIndex := chainIndex + i
+		if nextIndex >= len(m.chains) {
+			if !m.loopAround {
+				return nil
 			}
-			return nil
+			nextIndex -= len(m.chains)
+		}
+		if first := m.chains[nextIndex].First(); first != nil {
+			return first
 		}
-		// if it is the last panel in the chain, we need to get the next chain
-		nextChain := m.chains[chainIndex+1]
-		return nextChain.First()
 	}
-	return next
+	return nil
 }
 
+// Previous is the reverse of Next
 func (m *SelectorNode) Previous(panel IPanel) IPanel {
-	chain := m.chainMap[panel]
+	chain, ok := m.chainMap[panel]
+	if !ok {
+		return m.Last()
+	}
+	if previous := chain.Previous(panel); previous != nil {
+		return previous
+	}
+	if !m.sequential {
+		return nil
+	}
 	chainIndex := m.chainPosition[panel]
-	previous := chain.Previous(panel)
-	if previous == nil {
-		// if previous is nil, that means it is the first panel in the chain
-		if !m.sequential {
-			return nil // in sequential mode, we don't look at other chains
-		}
-		if chainIndex == 0 {
-			if m.loopAround {
-				return m.Last()
+	for i := 1; i <= len(m.chains); i++ {
+		previousIndex := chainIndex - i
+		if previousIndex < 0 {
+			if !m.loopAround {
+				return nil
 			}
-			return nil
+			previousIndex += len(m.chains)
+		}
+		if last := m.chains[previousIndex].Last(); last != nil {
+			return last
 		}
-		// if it is the first panel in the chain, we need to get the previous chain
-		previousChain := m.chains[chainIndex-1]
-		return previousChain.Last()
 	}
-	return previous
+	return nil
 }
 
 func (m *SelectorNode) First() IPanel {
 	firstOptions := make([]IPanel, 0)
+	for _, chain := range m.chains {
+		firstOptions = append(firstOptions, chain.First())
+	}
 	if m.sequential {
-		return m.chains[0].First()
-	} else {
-		for _, chain := range m.chains {
-			firstOptions = append(firstOptions, chain.First())
-		}
-		return m.selectionFunc(firstOptions)
+		return firstFocusable(firstOptions)
 	}
+	return m.selectionFunc(firstOptions)
 }
 
 func (m *SelectorNode) Last() IPanel {
 	lastOptions := make([]IPanel, 0)
 	if m.sequential {
-		return m.chains[len(m.chains)-1].Last()
-	} else {
-		for _, chain := range m.chains {
-			lastOptions = append(lastOptions, chain.Last())
+		for i := len(m.chains) - 1; i >= 0; i-- {
+			lastOptions = append(lastOptions, m.chains[i].Last())
 		}
-		return m.selectionFunc(lastOptions)
+		return firstFocusable(lastOptions)
+	}
+	for _, chain := range m.chains {
+		lastOptions = append(lastOptions, chain.Last())
 	}
+	return m.selectionFunc(lastOptions)
 }
 
 func (m *SelectorNode) contains(panel IPanel) bool {
@@ -241,11 +358,15 @@ func (m *SelectorNode) allPanels() []IPanel {
 	return allPanels
 }
 
-func visiblePaneSelectionLogic(panels []IPanel) IPanel {
+func firstFocusable(panels []IPanel) IPanel {
 	for _, panel := range panels {
-		if !panel.IsInHiddenTab() {
+		if IsPanelFocusable(panel) {
 			return panel
 		}
 	}
 	return nil
 }
+
+func visiblePaneSelectionLogic(panels []IPanel) IPanel {
+	return firstFocusable(panels)
+}
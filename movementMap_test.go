@@ -0,0 +1,97 @@
+package peanutbutter
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func panelName(panel IPanel) string {
+	if panel == nil {
+		return "<nil>"
+	}
+	return panel.GetName()
+}
+
+func TestPanelSequenceSkipsUnfocusablePanels(t *testing.T) {
+	l := newTestLayout(t)
+	l.b.SetEnabled(false)
+	empty := newTestPanel("empty") // never given a view
+	sequence := NewPanelSequence(l.a, l.b, empty, l.c, l.d)
+
+	for _, test := range []struct {
+		got  IPanel
+		want IPanel
+	}{
+		{sequence.Next(l.a), l.c},
+		{sequence.Next(l.c), nil}, // d is in a hidden tab
+		{sequence.Previous(l.c), l.a},
+		{sequence.Previous(l.a), nil},
+		{sequence.First(), l.a},
+		{sequence.Last(), l.c},
+	} {
+		if test.got != test.want {
+			t.Errorf("got %v, want %v", panelName(test.got), panelName(test.want))
+		}
+	}
+
+	l.b.SetEnabled(true)
+	if next := sequence.Next(l.a); next != l.b {
+		t.Errorf("next of a is %v once b is enabled again", panelName(next))
+	}
+}
+
+func TestLoopAroundPanelSequence(t *testing.T) {
+	l := newTestLayout(t)
+	sequence := NewLoopAroundPanelSequence(l.a, l.b, l.c, l.d)
+	if next := sequence.Next(l.c); next != l.a {
+		t.Errorf("next of c is %v, want a", panelName(next))
+	}
+	if previous := sequence.Previous(l.a); previous != l.c {
+		t.Errorf("previous of a is %v, want c", panelName(previous))
+	}
+	if next := sequence.Next(newTestPanel("other")); next != l.a {
+		t.Errorf("next of a panel outside the sequence is %v, want a", panelName(next))
+	}
+}
+
+func TestSequentialSelectorNode(t *testing.T) {
+	l := newTestLayout(t)
+	chains := []IMovementNode{NewPanelSequence(l.a), NewPanelSequence(l.d), NewPanelSequence(l.b, l.c)}
+	sequential := NewSequentialSelectorNode(chains...)
+	if next := sequential.Next(l.a); next != l.b {
+		t.Errorf("next of a is %v, want b past the hidden chain", panelName(next))
+	}
+	if next := sequential.Next(l.c); next != nil {
+		t.Errorf("next of c is %v without looping around", panelName(next))
+	}
+	if next := NewLoopAroundTabMap(chains...).Next(l.c); next != l.a {
+		t.Errorf("next of c is %v, want a when looping around", panelName(next))
+	}
+	if next := NewVisibleSelectorNode(chains...).Next(l.a); next != nil {
+		t.Errorf("visible selector moved from a to %v in another chain", panelName(next))
+	}
+}
+
+func TestAddMovementKeyBindings(t *testing.T) {
+	l := newTestLayout(t)
+	l.passKeys()
+	AddMovementKeyBindings(NewLoopAroundPanelSequence(l.a, l.b, l.c, l.d), l.top.ListPanel,
+		*NewKeyBinding(WithKeys("Ctrl+J"), WithEnabled(true)),
+		*NewKeyBinding(WithKeys("Ctrl+K"), WithEnabled(true)))
+	l.focus(l.a)
+	// b is disabled after the bindings were made
+	l.b.SetEnabled(false)
+	l.press(tcell.KeyCtrlJ, 0, tcell.ModCtrl)
+	if l.focused() != l.c {
+		t.Errorf("focus on %v, want c", panelName(l.focused()))
+	}
+	l.press(tcell.KeyCtrlJ, 0, tcell.ModCtrl)
+	if l.focused() != l.a {
+		t.Errorf("focus on %v, want a", panelName(l.focused()))
+	}
+	l.press(tcell.KeyCtrlK, 0, tcell.ModCtrl)
+	if l.focused() != l.c {
+		t.Errorf("focus on %v, want c", panelName(l.focused()))
+	}
+}
@@ -24,9 +24,11 @@ const (
 	Down
 	Left
 	Right
-	//StartWorkflow
-	//NextWorkflow
-	//PrevWorkflow
+	StartWorkflow
+	NextWorkflow
+	PrevWorkflow
+	FocusBack
+	FocusForward
 )
 
 type Msg interface {
@@ -65,6 +67,7 @@ type FocusRequestMsg struct {
 type ContextualHelpTextMsg struct {
 	Text string
 	Line int
+	Path []int // Path of the panel the help text belongs to
 }
 
 type RoutePath struct {
@@ -94,6 +97,8 @@ type KeyMsg struct {
 	*tcell.EventKey
 	Unused    *bool
 	Direction *PropagationDirection
+	Sequence  *KeySequenceState // set by the top level panel, nil if sequences are not tracked
+	Context   *KeyContext       // set by the top level panel, for evaluating when clauses
 }
 
 func (keyMsg KeyMsg) String() string {
@@ -129,13 +134,63 @@ func (keyMsg *KeyMsg) Matches(keyDef KeyDef) bool {
 	return keyDef.Matches(keyMsg.EventKey)
 }
 
+// IsSequencePending returns true if the key-stroke is part of
+// a partially typed key sequence, in which case it should only
+// be matched against key bindings and not handled otherwise
+func (keyMsg *KeyMsg) IsSequencePending() bool {
+	return keyMsg.Sequence.IsPending()
+}
+
+// MouseMsg is sent for tcell mouse events. X and Y are relative
+// to the view of the panel receiving the message; each ListPanel
+// converts them to the coordinates of the child it forwards to
+type MouseMsg struct {
+	*tcell.EventMouse
+	X int
+	Y int
+}
+
+// IsClick returns true if any of the primary, secondary or middle
+// buttons is pressed
+func (mouseMsg MouseMsg) IsClick() bool {
+	return mouseMsg.Buttons()&(tcell.ButtonPrimary|tcell.ButtonSecondary|tcell.ButtonMiddle) != 0
+}
+
+// PasteMsg carries the text of a bracketed paste. It is delivered
+// along the focus path in one piece, without being matched against
+// key bindings. Leaf models get it as is; see MapPasteMsg
+type PasteMsg struct {
+	Text string
+}
+
+// PushGlobalKeyLayerMsg asks the top level panel to push a key layer
+// whose bindings apply regardless of which panel is focused
+type PushGlobalKeyLayerMsg struct {
+	Layer *KeyLayer
+}
+
+// SetContextValueMsg asks the top level panel to set an app-defined
+// value that when clauses of key bindings can refer to.
+// A nil Value removes the key
+type SetContextValueMsg struct {
+	Key   string
+	Value any
+}
+
+// PopGlobalKeyLayerMsg asks the top level panel to pop its active global key layer
+type PopGlobalKeyLayerMsg struct{}
+
 type ConsiderForLocalShortcutMsg struct {
 	KeyMsg
 	*RoutePath
 }
 
+// ConsiderForGlobalShortcutMsg is routed by the top level panel to
+// each panel in turn, giving its global key bindings a chance at
+// the key before it is delivered along the focus path
 type ConsiderForGlobalShortcutMsg struct {
 	KeyMsg
+	RoutePath
 }
 
 type AutoRoutedMsg struct {
@@ -147,13 +202,19 @@ type BroadcastMsg struct {
 	Msg
 }
 
+// SelectedTabIndexMsg is broadcast when a ZStacked ListPanel
+// selects another tab
 type SelectedTabIndexMsg struct {
 	Index         int
+	TabName       string
 	ListPanelName string
 }
 
+// SelectTabIndexMsg asks the ZStacked ListPanel called ListPanelName
+// to select a tab, by TabName if it is set and by Index otherwise
 type SelectTabIndexMsg struct {
 	Index         int
+	TabName       string
 	ListPanelName string
 }
 
@@ -184,16 +245,50 @@ func GetMessageHandlingType(msg Msg) Msg {
 		return RoutedMsgType{Msg: msg, RoutePath: msg.RoutePath}
 	case FocusRevokeMsg:
 		return BroadcastMsgType{Msg: msg}
+	case KeySequencePendingMsg:
+		return BroadcastMsgType{Msg: msg}
+	case SelectTabIndexMsg:
+		return BroadcastMsgType{Msg: msg}
+	case SelectedTabIndexMsg:
+		return BroadcastMsgType{Msg: msg}
+	case TabAddedMsg:
+		return BroadcastMsgType{Msg: msg}
+	case TabRemovedMsg:
+		return BroadcastMsgType{Msg: msg}
+	case TabMovedMsg:
+		return BroadcastMsgType{Msg: msg}
 	case FocusRequestMsg:
 		return RequestMsgType{Msg: msg}
 	case ContextualHelpTextMsg:
 		return RequestMsgType{Msg: msg}
+	case PushGlobalKeyLayerMsg:
+		return RequestMsgType{Msg: msg}
+	case PopGlobalKeyLayerMsg:
+		return RequestMsgType{Msg: msg}
+	case SetContextValueMsg:
+		return RequestMsgType{Msg: msg}
+	case ShowOverlayMsg:
+		return RequestMsgType{Msg: msg}
+	case HideOverlayMsg:
+		return RequestMsgType{Msg: msg}
+	case StartWorkflowMsg:
+		return RequestMsgType{Msg: msg}
+	case AbandonWorkflowMsg:
+		return RequestMsgType{Msg: msg}
+	case WorkflowChangedMsg:
+		return BroadcastMsgType{Msg: msg}
 	case AutoRoutedMsg:
 		return RoutedMsgType{Msg: msg, RoutePath: msg.RoutePath}
+	case ConsiderForGlobalShortcutMsg:
+		return RoutedMsgType{Msg: msg, RoutePath: msg.RoutePath}
 	case KeyMsg:
 		return FocusPropagatedMsgType{Msg: msg}
+	case PasteMsg:
+		return FocusPropagatedMsgType{Msg: msg}
 	case ResizeMsg:
 		return msg
+	case MouseMsg:
+		return msg
 	default:
 		return UntypedMsgType{Msg: msg}
 	}
@@ -0,0 +1,80 @@
+package peanutbutter
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gdamore/tcell/v2"
+)
+
+// maxNumberedTabs is the number of tabs that get a numbered
+// key binding, one for each of the keys 1 to 9
+const maxNumberedTabs = 9
+
+type numberedTabKeys struct {
+	modifiers   tcell.ModMask
+	keyBindings []*KeyBinding
+}
+
+// WithNumberedTabKeyBindings adds a key binding for each of the first
+// nine tabs of a ZStacked ListPanel, the digit of its position with
+// the given modifiers, e.g. tcell.ModAlt for Alt+1 to Alt+9. The help
+// texts are taken from the tab names and the bindings follow the tabs
+// as they are added, removed or moved. The bindings take the key
+// before the focused panel inside the list does. Their action IDs are
+// tabs.select.1 to tabs.select.9, so a keymap can remap them
+func WithNumberedTabKeyBindings(modifiers tcell.ModMask) ListPanelOption {
+	return func(m *ListPanel) {
+		m.numberedTabs = &numberedTabKeys{modifiers: modifiers}
+		m.updateNumberedTabKeyBindings()
+	}
+}
+
+// updateNumberedTabKeyBindings makes the numbered tab bindings match
+// the current tabs. The binding of each position is kept, along with
+// its keys, and only gets the help texts of the tab now at its place
+func (m *ListPanel) updateNumberedTabKeyBindings() {
+	if m.numberedTabs == nil {
+		return
+	}
+	old := make(map[*KeyBinding]bool, len(m.numberedTabs.keyBindings))
+	for _, keyBinding := range m.numberedTabs.keyBindings {
+		old[keyBinding] = true
+	}
+	keyBindings := []*KeyBinding{}
+	for _, keyBinding := range m.KeyBindings {
+		if !old[keyBinding] {
+			keyBindings = append(keyBindings, keyBinding)
+		}
+	}
+
+	count := min(len(m.Panels), maxNumberedTabs)
+	added := []*KeyBinding{}
+	for i := len(m.numberedTabs.keyBindings); i < count; i++ {
+		index := i
+		keyBinding := NewKeyBinding(
+			WithActionID(fmt.Sprintf("tabs.select.%d", i+1)),
+			WithKeyDef(KeyDef{Key: tcell.KeyRune, Rune: rune('1' + i), Modifiers: m.numberedTabs.modifiers}),
+			WithEnabled(true),
+			WithFunc(func() tea.Cmd {
+				return m.SetSelected(index)
+			}),
+		)
+		// taken before the focused panel, which would use up the key
+		keyBinding.Override = true
+		added = append(added, keyBinding)
+	}
+	if m.keyMap != nil && len(added) > 0 {
+		if _, err := m.keyMap.ApplyToKeyBindings(added); err != nil {
+			DebugPrintf("ListPanel %v: %v\n", m.path, err)
+		}
+	}
+	m.numberedTabs.keyBindings = append(m.numberedTabs.keyBindings[:min(count, len(m.numberedTabs.keyBindings))], added...)
+
+	for i, keyBinding := range m.numberedTabs.keyBindings {
+		name := m.Panels[i].GetName()
+		keyBinding.ShortHelp = name
+		keyBinding.LongHelp = fmt.Sprintf("Switch to tab %d: %s", i+1, name)
+	}
+	m.KeyBindings = append(keyBindings, m.numberedTabs.keyBindings...)
+}
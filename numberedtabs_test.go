@@ -0,0 +1,86 @@
+package peanutbutter
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func newNumberedTabsLayout(t *testing.T, options ...TopLevelListPanelOption) *testLayout {
+	t.Helper()
+	l := newTestLayout(t, options...)
+	WithNumberedTabKeyBindings(tcell.ModAlt)(l.tabs)
+	return l
+}
+
+func TestNumberedTabKeysBeatFocusedPanel(t *testing.T) {
+	l := newNumberedTabsLayout(t)
+	l.focus(l.c)
+	l.press(tcell.KeyRune, '2', tcell.ModAlt)
+	if l.tabs.GetSelected() != l.d || l.focused() != l.d {
+		t.Errorf("%v selected, focus on %v", l.tabs.GetSelected().GetName(), l.focused().GetName())
+	}
+	if keyMsgs := l.leaf(l.c).keyMsgs(); len(keyMsgs) != 0 {
+		t.Errorf("focused c got %d keys", len(keyMsgs))
+	}
+	l.press(tcell.KeyRune, '1', tcell.ModAlt)
+	if l.tabs.GetSelected() != l.c {
+		t.Errorf("%v selected", l.tabs.GetSelected().GetName())
+	}
+}
+
+func TestNumberedTabKeysNeedFocusInList(t *testing.T) {
+	l := newNumberedTabsLayout(t)
+	l.focus(l.a)
+	l.press(tcell.KeyRune, '2', tcell.ModAlt)
+	if l.tabs.GetSelected() != l.c {
+		t.Errorf("%v selected with the focus outside the list", l.tabs.GetSelected().GetName())
+	}
+}
+
+func TestNumberedTabKeysFollowTabs(t *testing.T) {
+	l := newNumberedTabsLayout(t)
+	l.runTabCmd(l.tabs.AddTab(newTestPanel("e"), 0))
+	l.runTabCmd(l.tabs.MoveTab(2, 1))
+	help := []string{}
+	for _, keyBinding := range l.tabs.numberedTabs.keyBindings {
+		help = append(help, keyBinding.renderKeys()+" "+keyBinding.ShortHelp)
+	}
+	want := []string{"Alt+1 e", "Alt+2 d", "Alt+3 c"}
+	if len(help) != len(want) || len(l.tabs.KeyBindings) != len(want) {
+		t.Fatalf("bindings %v, %d in the list", help, len(l.tabs.KeyBindings))
+	}
+	for i := range want {
+		if help[i] != want[i] {
+			t.Errorf("binding %d is %q, want %q", i, help[i], want[i])
+		}
+	}
+
+	l.focus(l.c)
+	l.press(tcell.KeyRune, '1', tcell.ModAlt)
+	if l.tabs.GetSelectedIndex() != 0 {
+		t.Errorf("tab %d selected", l.tabs.GetSelectedIndex())
+	}
+}
+
+func TestNumberedTabKeysKeepRemaps(t *testing.T) {
+	l := newNumberedTabsLayout(t, WithKeyMapConfig(KeyMapConfig{"tabs.select.3": {"F3"}}))
+	first := l.tabs.numberedTabs.keyBindings[0]
+	if first.ActionID != "tabs.select.1" {
+		t.Errorf("action ID %q", first.ActionID)
+	}
+	first.KeyDefs = []KeyDef{{Key: tcell.KeyF1}}
+
+	l.runTabCmd(l.tabs.AddTab(newTestPanel("e"), 0))
+	keyBindings := l.tabs.numberedTabs.keyBindings
+	if len(keyBindings) != 3 || keyBindings[0] != first {
+		t.Fatalf("bindings %v", keyBindings)
+	}
+	got := []string{}
+	for _, keyBinding := range keyBindings {
+		got = append(got, keyBinding.renderKeys()+" "+keyBinding.ShortHelp)
+	}
+	if got[0] != "F1 e" || got[1] != "Alt+2 c" || got[2] != "F3 d" {
+		t.Errorf("bindings %q", got)
+	}
+}
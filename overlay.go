@@ -0,0 +1,239 @@
+package peanutbutter
+
+import (
+	tcellviews "github.com/gdamore/tcell/v2/views"
+)
+
+// Rect is a rectangle on screen
+type Rect struct {
+	X      int
+	Y      int
+	Width  int
+	Height int
+}
+
+// OverlayPlacement decides where an overlay is drawn, given
+// the rectangle of the whole top level panel and that of the
+// focused panel, both relative to the top level panel
+type OverlayPlacement func(screen Rect, focused Rect) Rect
+
+// CenteredOverlay places an overlay in the middle of the screen,
+// taking up the given fractions of its width and height
+func CenteredOverlay(widthRatio float64, heightRatio float64) OverlayPlacement {
+	return func(screen Rect, focused Rect) Rect {
+		w := int(float64(screen.Width) * widthRatio)
+		h := int(float64(screen.Height) * heightRatio)
+		return Rect{
+			X:      screen.X + (screen.Width-w)/2,
+			Y:      screen.Y + (screen.Height-h)/2,
+			Width:  w,
+			Height: h,
+		}
+	}
+}
+
+// clampRect moves and shrinks r so it fits inside bounds
+func clampRect(r Rect, bounds Rect) Rect {
+	if r.Width > bounds.Width {
+		r.Width = bounds.Width
+	}
+	if r.Height > bounds.Height {
+		r.Height = bounds.Height
+	}
+	if r.X+r.Width > bounds.X+bounds.Width {
+		r.X = bounds.X + bounds.Width - r.Width
+	}
+	if r.Y+r.Height > bounds.Y+bounds.Height {
+		r.Y = bounds.Y + bounds.Height - r.Height
+	}
+	if r.X < bounds.X {
+		r.X = bounds.X
+	}
+	if r.Y < bounds.Y {
+		r.Y = bounds.Y
+	}
+	return r
+}
+
+// ShowOverlayMsg asks the top level panel to draw a panel on
+// top of all others. A modal overlay is focused and receives all
+// key-strokes and mouse events until it is hidden
+type ShowOverlayMsg struct {
+	Panel     IPanel
+	Placement OverlayPlacement
+	Modal     bool
+}
+
+// HideOverlayMsg asks the top level panel to remove an overlay
+// If Panel is nil, the topmost overlay is removed
+type HideOverlayMsg struct {
+	Panel IPanel
+}
+
+type overlay struct {
+	panel     IPanel
+	placement OverlayPlacement
+	modal     bool
+}
+
+// ShowOverlay draws panel on top of all other panels at the
+// position given by placement. The panel gets a path of its own
+// outside of the panel hierarchy, so its routed messages reach it
+func (m *TopLevelListPanel) ShowOverlay(panel IPanel, placement OverlayPlacement, modal bool) {
+	m.nextOverlayID++
+	o := &overlay{panel: panel, placement: placement, modal: modal}
+	panel.SetView(tcellviews.NewViewPort(m.view, 0, 0, -1, -1))
+	panel.SetPath([]int{-m.nextOverlayID})
+	panel.Init(m.cmds)
+	m.overlays = append(m.overlays, o)
+	m.placeOverlay(o)
+	if modal {
+		panel.HandleMessage(FocusGrantMsg{RoutePath: RoutePath{Path: panel.GetPath()}, Relation: Self})
+	}
+	m.overlaysChanged = true
+}
+
+// HideOverlay removes the overlay showing panel, or the topmost
+// overlay if panel is nil
+func (m *TopLevelListPanel) HideOverlay(panel IPanel) {
+	for i := len(m.overlays) - 1; i >= 0; i-- {
+		if panel == nil || m.overlays[i].panel == panel {
+			m.overlays[i].panel.HandleMessage(FocusRevokeMsg{})
+			m.overlays = append(m.overlays[:i], m.overlays[i+1:]...)
+			m.overlaysChanged = true
+			return
+		}
+	}
+}
+
+// IsOverlayShown returns true if panel is currently shown as an overlay
+func (m *TopLevelListPanel) IsOverlayShown(panel IPanel) bool {
+	for _, o := range m.overlays {
+		if o.panel == panel {
+			return true
+		}
+	}
+	return false
+}
+
+// modalOverlay returns the topmost modal overlay or nil
+func (m *TopLevelListPanel) modalOverlay() *overlay {
+	for i := len(m.overlays) - 1; i >= 0; i-- {
+		if m.overlays[i].modal {
+			return m.overlays[i]
+		}
+	}
+	return nil
+}
+
+func (m *TopLevelListPanel) findOverlay(path []int) *overlay {
+	for _, o := range m.overlays {
+		if IsPathPrefix(o.panel.GetPath(), path) {
+			return o
+		}
+	}
+	return nil
+}
+
+// screenRect returns the rectangle of the top level panel
+// in its own coordinates
+func (m *TopLevelListPanel) screenRect() Rect {
+	if m.view == nil {
+		return Rect{}
+	}
+	w, h := m.view.Size()
+	return Rect{Width: w, Height: h}
+}
+
+// PanelRect returns the rectangle of panel relative to the top level panel
+func (m *TopLevelListPanel) PanelRect(panel IPanel) Rect {
+	if m.view == nil {
+		return Rect{}
+	}
+	px, py, _, _ := m.view.GetPhysical()
+	for _, rect := range panelRects(m.ListPanel, -px, -py, []panelRect{}) {
+		if rect.panel == panel {
+			return Rect{X: rect.x0, Y: rect.y0, Width: rect.x1 - rect.x0 + 1, Height: rect.y1 - rect.y0 + 1}
+		}
+	}
+	return Rect{}
+}
+
+func (m *TopLevelListPanel) focusedRect() Rect {
+	focusPath := FocusPath(m.ListPanel)
+	if len(focusPath) == 0 {
+		return m.screenRect()
+	}
+	return m.PanelRect(focusPath[len(focusPath)-1])
+}
+
+func (m *TopLevelListPanel) placeOverlay(o *overlay) {
+	screen := m.screenRect()
+	r := clampRect(o.placement(screen, m.focusedRect()), screen)
+	o.panel.HandleMessage(ResizeMsg{X: r.X, Y: r.Y, Width: r.Width, Height: r.Height})
+}
+
+// handleOverlayMsg delivers messages meant for overlays and returns
+// true if the message was consumed
+func (m *TopLevelListPanel) handleOverlayMsg(msg Msg) bool {
+	switch msg := msg.(type) {
+	case ResizeMsg:
+		m.ListPanel.HandleMessage(msg)
+		for _, o := range m.overlays {
+			m.placeOverlay(o)
+		}
+		m.overlaysChanged = true
+		return true
+
+	case MouseMsg:
+		o := m.modalOverlay()
+		if o == nil {
+			return false
+		}
+		if x, y, inside := viewContains(o.panel.GetView(), msg.X, msg.Y); inside {
+			o.panel.HandleMessage(MouseMsg{EventMouse: msg.EventMouse, X: x, Y: y})
+		}
+		return true
+
+	case PasteMsg:
+		o := m.modalOverlay()
+		if o == nil {
+			return false
+		}
+		o.panel.HandleMessage(msg)
+		return true
+
+	case ShowOverlayMsg:
+		m.ShowOverlay(msg.Panel, msg.Placement, msg.Modal)
+		return true
+
+	case HideOverlayMsg:
+		m.HideOverlay(msg.Panel)
+		return true
+	}
+
+	if routed, ok := GetMessageHandlingType(msg).(RoutedMsgType); ok {
+		path := routed.GetRoutePath().Path
+		if len(path) > 0 && path[0] < 0 {
+			if o := m.findOverlay(path); o != nil {
+				o.panel.HandleMessage(routed.Msg)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+func (m *TopLevelListPanel) Draw(force bool) bool {
+	if m.overlaysChanged {
+		force = true
+		m.overlaysChanged = false
+	}
+	redrawn := m.ListPanel.Draw(force) || force
+	for _, o := range m.overlays {
+		if o.panel.Draw(redrawn) {
+			redrawn = true
+		}
+	}
+	return redrawn
+}
@@ -0,0 +1,72 @@
+package peanutbutter
+
+// WalkPanels visits panel and all its descendants depth first,
+// in path order. If fn returns false, the children of the visited
+// panel are skipped
+func WalkPanels(panel IPanel, fn func(IPanel) bool) {
+	if !fn(panel) {
+		return
+	}
+	if parent, ok := panel.(IPanelWithChildren); ok {
+		for _, child := range parent.GetChildren() {
+			WalkPanels(child, fn)
+		}
+	}
+}
+
+// FindPanelByPath returns the panel in the hierarchy under root
+// identified by path, or nil if there is no such panel
+func FindPanelByPath(root IPanel, path []int) IPanel {
+	var found IPanel
+	WalkPanels(root, func(panel IPanel) bool {
+		if found != nil {
+			return false
+		}
+		if IsSamePath(panel.GetPath(), path) {
+			found = panel
+			return false
+		}
+		return IsPathPrefix(panel.GetPath(), path)
+	})
+	return found
+}
+
+// FocusPath returns the focused panels from root down to
+// the most deeply nested focused panel
+func FocusPath(root IPanel) []IPanel {
+	path := []IPanel{}
+	WalkPanels(root, func(panel IPanel) bool {
+		if !panel.IsFocused() {
+			return false
+		}
+		path = append(path, panel)
+		return true
+	})
+	return path
+}
+
+// IsPathPrefix returns true if path starts with prefix
+func IsPathPrefix(prefix, path []int) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	return IsSamePath(prefix, path[:len(prefix)])
+}
+
+// IsLeafPanel returns true if the panel does not house other panels
+func IsLeafPanel(panel IPanel) bool {
+	parent, ok := panel.(IPanelWithChildren)
+	return !ok || len(parent.GetChildren()) == 0
+}
+
+// LeafPanels returns all leaf panels under root in path order
+func LeafPanels(root IPanel) []IPanel {
+	leaves := []IPanel{}
+	WalkPanels(root, func(panel IPanel) bool {
+		if IsLeafPanel(panel) {
+			leaves = append(leaves, panel)
+		}
+		return true
+	})
+	return leaves
+}
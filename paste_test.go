@@ -0,0 +1,112 @@
+package peanutbutter
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gdamore/tcell/v2"
+	tcellviews "github.com/gdamore/tcell/v2/views"
+)
+
+type recordingRootModel struct {
+	msgs []Msg
+}
+
+func (m *recordingRootModel) Update(msg Msg) { m.msgs = append(m.msgs, msg) }
+func (m *recordingRootModel) Init(cmds chan tea.Cmd, view *tcellviews.ViewPort) tea.Cmd {
+	return nil
+}
+func (m *recordingRootModel) Draw() bool { return false }
+
+func TestRunnerCollectsBracketedPaste(t *testing.T) {
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	model := &recordingRootModel{}
+	runner := &pbRunModel{model: model, s: screen}
+	runner.update(tcell.NewEventPaste(true))
+	for _, r := range "hi" {
+		runner.update(tcell.NewEventKey(tcell.KeyRune, r, 0))
+	}
+	runner.update(tcell.NewEventKey(tcell.KeyEnter, 0, 0))
+	runner.update(tcell.NewEventKey(tcell.KeyRune, 'x', 0))
+	runner.update(tcell.NewEventPaste(false))
+
+	if len(model.msgs) != 1 {
+		t.Fatalf("got %d messages, want one PasteMsg: %v", len(model.msgs), model.msgs)
+	}
+	if pasteMsg, ok := model.msgs[0].(PasteMsg); !ok || pasteMsg.Text != "hi\nx" {
+		t.Errorf("got %#v", model.msgs[0])
+	}
+}
+
+func TestPasteGoesToFocusedLeafOnly(t *testing.T) {
+	l := newTestLayout(t)
+	fired := false
+	l.a.AddKeyBinding(SingleRuneBinding('h').SetFunc(func() tea.Cmd {
+		fired = true
+		return nil
+	}))
+	l.focus(l.a)
+	l.top.HandleMessage(PasteMsg{Text: "hello"})
+	l.pump()
+
+	if fired {
+		t.Error("pasted text triggered a key binding")
+	}
+	pasted := []string{}
+	for _, msg := range l.leaf(l.a).msgs {
+		if pasteMsg, ok := msg.(PasteMsg); ok {
+			pasted = append(pasted, pasteMsg.Text)
+		}
+	}
+	if len(pasted) != 1 || pasted[0] != "hello" {
+		t.Errorf("a got %q", pasted)
+	}
+	for _, msg := range l.leaf(l.b).msgs {
+		if _, ok := msg.(PasteMsg); ok {
+			t.Error("unfocused b got the paste")
+		}
+	}
+}
+
+func TestMapPasteMsg(t *testing.T) {
+	keyMsg := MapPasteMsg(PasteMsg{Text: "abc"})
+	if !keyMsg.Paste || string(keyMsg.Runes) != "abc" || keyMsg.Type != tea.KeyRunes {
+		t.Errorf("got %+v", keyMsg)
+	}
+}
+
+// textInputLeaf wraps a bubbles textinput the way leaf models are
+// expected to, mapping key-strokes and pastes to bubbletea messages
+type textInputLeaf struct {
+	input textinput.Model
+}
+
+func (m *textInputLeaf) Init() tea.Cmd { return nil }
+
+func (m *textInputLeaf) Update(msg Msg) tea.Cmd {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case KeyMsg:
+		if keyMsg, ok := MapKeyMsg(msg); ok {
+			m.input, cmd = m.input.Update(keyMsg)
+		}
+	case PasteMsg:
+		m.input, cmd = m.input.Update(MapPasteMsg(msg))
+	}
+	return cmd
+}
+
+func TestPasteIntoTextInput(t *testing.T) {
+	leaf := &textInputLeaf{input: textinput.New()}
+	leaf.input.Focus()
+	panel := NewShortCutPanel(leaf)
+	panel.Init(make(chan tea.Cmd, 10))
+	panel.HandleMessage(PasteMsg{Text: "hello world"})
+	if value := leaf.input.Value(); value != "hello world" {
+		t.Errorf("text input holds %q", value)
+	}
+}
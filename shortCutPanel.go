@@ -11,6 +11,7 @@ const (
 
 type ShortCutPanelConfig struct {
 	ContextualHelp string
+	TabBadge       string
 	Title          string
 	TitleStyle     TitleStyle
 	PanelStyle     PanelStyle
@@ -40,6 +41,8 @@ type ShortCutPanel struct {
 	MarkMessageNotUsed func(msg *KeyMsg)
 	modelView          *tcellviews.ViewPort
 	tabHidden          bool
+	disabled           bool
+	KeyLayerStack
 }
 
 type ShortCutPanelOption func(*ShortCutPanel)
@@ -50,6 +53,12 @@ func WithContextualHelp(help string) ShortCutPanelOption {
 	}
 }
 
+func WithTabBadge(badge string) ShortCutPanelOption {
+	return func(config *ShortCutPanel) {
+		config.TabBadge = badge
+	}
+}
+
 func WithTitle(title string) ShortCutPanelOption {
 	return func(config *ShortCutPanel) {
 		config.Title = title
@@ -108,6 +117,10 @@ func (p *ShortCutPanel) AddKeyBinding(kb *KeyBinding) {
 	p.KeyBindings = append(p.KeyBindings, kb)
 }
 
+func (p *ShortCutPanel) GetKeyBindings() []*KeyBinding {
+	return p.KeyBindings
+}
+
 func (p *ShortCutPanel) SetPath(path []int) {
 	p.path = make([]int, len(path))
 	copy(p.path, path)
@@ -125,6 +138,22 @@ func (p *ShortCutPanel) GetName() string {
 	return p.Name
 }
 
+// GetTabBadge returns the badge of the model if it has one,
+// and the configured TabBadge otherwise
+func (p *ShortCutPanel) GetTabBadge() string {
+	if model, ok := p.Model.(ILeafModelWithTabBadge); ok {
+		if badge := model.TabBadge(); badge != "" {
+			return badge
+		}
+	}
+	return p.TabBadge
+}
+
+func (p *ShortCutPanel) SetTabBadge(badge string) {
+	p.TabBadge = badge
+	p.redraw = true
+}
+
 func (p *ShortCutPanel) SetView(view *tcellviews.ViewPort) {
 	p.view = view
 	p.modelView = tcellviews.NewViewPort(p.view, 0, 0, -1, -1)
@@ -201,13 +230,17 @@ func (p *ShortCutPanel) Init(cmds chan tea.Cmd) {
 		batchCmds = append(batchCmds, cmd)
 	}
 	if p.IsFocused() {
-		batchCmds = append(batchCmds, func() tea.Msg {
-			return ContextualHelpTextMsg{Text: p.ContextualHelp}
-		})
+		batchCmds = append(batchCmds, p.contextualHelpCmd())
 	}
 	cmds <- tea.Batch(batchCmds...)
 }
 
+func (p *ShortCutPanel) contextualHelpCmd() tea.Cmd {
+	return func() tea.Msg {
+		return ContextualHelpTextMsg{Text: p.ContextualHelp, Path: p.GetPath()}
+	}
+}
+
 func (p *ShortCutPanel) FocusRequestCmd(direction Relation) tea.Cmd {
 	return func() tea.Msg {
 		return FocusRequestMsg{
@@ -219,7 +252,7 @@ func (p *ShortCutPanel) FocusRequestCmd(direction Relation) tea.Cmd {
 
 func (p *ShortCutPanel) HandleKeybindings(msg KeyMsg, onlyOverrides bool) tea.Cmd {
 	DebugPrintf("ShortCutPanel received message from child: %T %+v\n", msg, msg)
-	return KeyBindingsHandler(p.KeyBindings, msg, onlyOverrides)
+	return KeyBindingsHandler(p.ActiveKeyBindings(p.KeyBindings), msg, onlyOverrides)
 }
 
 func (p *ShortCutPanel) HandleMessage(msg Msg) {
@@ -230,9 +263,16 @@ func (p *ShortCutPanel) HandleMessage(msg Msg) {
 		p.HandleSizeMsg(msg)
 	case AutoRoutedMsg:
 		cmd = p.Model.Update(msg.Msg)
+	case MouseMsg:
+		p.HandleMouseMsg(msg)
+	case PasteMsg:
+		cmd = p.Model.Update(msg)
+	case ConsiderForGlobalShortcutMsg:
+		cmd = GlobalKeyBindingsHandler(p.ActiveKeyBindings(p.KeyBindings), msg.KeyMsg)
 	case FocusGrantMsg:
 		p.focus = true
 		p.redraw = true
+		p.cmds <- p.contextualHelpCmd()
 		cmd = p.Model.Update(msg)
 	case FocusRevokeMsg:
 		p.focus = false
@@ -246,6 +286,12 @@ func (p *ShortCutPanel) HandleMessage(msg Msg) {
 				p.cmds <- tea.Batch(cmds...)
 				return
 			}
+			if keyMsg.IsSequencePending() {
+				// keys of a pending sequence never reach the model
+				cmds = append(cmds, p.HandleKeybindings(keyMsg, false))
+				p.cmds <- p.RoutedCmd(tea.Batch(cmds...))
+				return
+			}
 			keyMsg.SetUsed()
 		}
 		cmds = append(cmds, p.Model.Update(msg))
@@ -261,6 +307,22 @@ func (p *ShortCutPanel) HandleMessage(msg Msg) {
 	}
 }
 
+// HandleMouseMsg requests focus when the panel is clicked and
+// passes the message on to the model if it is inside the model's view
+func (p *ShortCutPanel) HandleMouseMsg(msg MouseMsg) {
+	if msg.IsClick() && !p.IsFocused() {
+		p.cmds <- p.FocusRequestCmd(Self)
+	}
+	x, y, inside := viewContains(p.modelView, msg.X, msg.Y)
+	if !inside {
+		return
+	}
+	cmd := p.Model.Update(MouseMsg{EventMouse: msg.EventMouse, X: x, Y: y})
+	if cmd != nil {
+		p.cmds <- p.RoutedCmd(cmd)
+	}
+}
+
 func (p *ShortCutPanel) HandleSizeMsg(msg ResizeMsg) tea.Cmd {
 	DebugPrintf("ShortCutPanel received size message: %+v\n", msg)
 	p.redraw = true
@@ -275,6 +337,17 @@ func (p *ShortCutPanel) HandleSizeMsg(msg ResizeMsg) tea.Cmd {
 	return cmd
 }
 
+func (p *ShortCutPanel) IsEnabled() bool {
+	return !p.disabled
+}
+
+// SetEnabled enables or disables the panel. Focus movement
+// skips disabled panels
+func (p *ShortCutPanel) SetEnabled(enabled bool) {
+	p.disabled = !enabled
+	p.redraw = true
+}
+
 func (p *ShortCutPanel) IsInHiddenTab() bool {
 	return p.tabHidden
 }
@@ -0,0 +1,122 @@
+package peanutbutter
+
+// panelRect is the on-screen rectangle occupied by a panel
+// x1 and y1 are inclusive
+type panelRect struct {
+	panel IPanel
+	x0    int
+	y0    int
+	x1    int
+	y1    int
+}
+
+func (r panelRect) isEmpty() bool {
+	return r.x1 < r.x0 || r.y1 < r.y0
+}
+
+// panelRects returns the on-screen rectangles of panel and all its
+// descendants. x and y are the screen offset of the view that
+// panel's view is placed in
+func panelRects(panel IPanel, x int, y int, rects []panelRect) []panelRect {
+	view := panel.GetView()
+	if view == nil {
+		return rects
+	}
+	px, py, pX, pY := view.GetPhysical()
+	rects = append(rects, panelRect{panel: panel, x0: x + px, y0: y + py, x1: x + pX, y1: y + pY})
+	if parent, ok := panel.(IPanelWithChildren); ok {
+		for _, child := range parent.GetChildren() {
+			rects = panelRects(child, x+px, y+py, rects)
+		}
+	}
+	return rects
+}
+
+// rangeGap returns the distance between the ranges [a0, a1] and [b0, b1]
+// or 0 if they overlap
+func rangeGap(a0, a1, b0, b1 int) int {
+	if b0 > a1 {
+		return b0 - a1
+	}
+	if a0 > b1 {
+		return a0 - b1
+	}
+	return 0
+}
+
+func absInt(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+// directionalDistance measures how far to is from from when moving
+// in direction. The first value is the distance along the direction
+// plus a penalty for not being aligned with from, the second breaks
+// ties by how far the centers are apart across the direction.
+// ok is false if to does not lie in that direction
+func directionalDistance(from panelRect, to panelRect, direction Relation) (int, int, bool) {
+	var primary, gap, skew int
+	switch direction {
+	case Left:
+		primary = from.x0 - to.x1
+	case Right:
+		primary = to.x0 - from.x1
+	case Up:
+		primary = from.y0 - to.y1
+	case Down:
+		primary = to.y0 - from.y1
+	default:
+		return 0, 0, false
+	}
+	if primary <= 0 {
+		return 0, 0, false
+	}
+	if direction == Left || direction == Right {
+		gap = rangeGap(from.y0, from.y1, to.y0, to.y1)
+		skew = absInt((from.y0 + from.y1) - (to.y0 + to.y1))
+	} else {
+		gap = rangeGap(from.x0, from.x1, to.x0, to.x1)
+		skew = absInt((from.x0 + from.x1) - (to.x0 + to.x1))
+	}
+	return primary + 2*gap, skew, true
+}
+
+// FindPanelInDirection returns the visible leaf panel nearest to the panel
+// identified by path in the given direction (Up, Down, Left or Right),
+// or nil if there is none
+func FindPanelInDirection(root IPanel, path []int, direction Relation) IPanel {
+	rects := panelRects(root, 0, 0, []panelRect{})
+	var from *panelRect
+	for i := range rects {
+		if IsSamePath(rects[i].panel.GetPath(), path) {
+			from = &rects[i]
+			break
+		}
+	}
+	if from == nil {
+		return nil
+	}
+
+	var best IPanel
+	bestDistance, bestSkew := 0, 0
+	for _, rect := range rects {
+		if !IsLeafPanel(rect.panel) || rect.panel.IsInHiddenTab() || rect.isEmpty() {
+			continue
+		}
+		if IsPathPrefix(path, rect.panel.GetPath()) {
+			continue
+		}
+		distance, skew, ok := directionalDistance(*from, rect, direction)
+		if !ok {
+			continue
+		}
+		if best == nil || distance < bestDistance || (distance == bestDistance && skew < bestSkew) {
+			best = rect.panel
+			bestDistance = distance
+			bestSkew = skew
+		}
+	}
+	return best
+}
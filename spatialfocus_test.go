@@ -0,0 +1,62 @@
+package peanutbutter
+
+import "testing"
+
+func TestFindPanelInDirection(t *testing.T) {
+	l := newTestLayout(t)
+	tests := []struct {
+		from      IPanel
+		direction Relation
+		want      IPanel
+	}{
+		{l.a, Right, l.b},
+		{l.b, Down, l.c},
+		{l.c, Up, l.b},
+		{l.c, Left, l.a},
+		{l.b, Left, l.a},
+		{l.a, Left, nil},
+		{l.a, Up, nil},
+		{l.c, Down, nil},
+	}
+	for _, test := range tests {
+		got := FindPanelInDirection(l.top.ListPanel, test.from.GetPath(), test.direction)
+		if got != test.want {
+			t.Errorf("%v of %s: got %v, want %v", test.direction, test.from.GetName(), got, test.want)
+		}
+	}
+}
+
+func TestFindPanelInDirectionSkipsHiddenTabs(t *testing.T) {
+	l := newTestLayout(t)
+	l.top.cmds <- l.tabs.SetSelected(1)
+	l.pump()
+	if got := FindPanelInDirection(l.top.ListPanel, l.b.GetPath(), Down); got != l.d {
+		t.Errorf("down of b: got %v, want the visible tab d", got)
+	}
+}
+
+func TestDirectionalFocusRequest(t *testing.T) {
+	l := newTestLayout(t)
+	l.focus(l.a)
+	l.top.HandleMessage(FocusRequestMsg{RequestedPath: l.a.GetPath(), Relation: Right})
+	l.pump()
+	if l.focused() != l.b {
+		t.Fatalf("focused %v, want b", l.focused())
+	}
+	l.top.HandleMessage(FocusRequestMsg{RequestedPath: l.b.GetPath(), Relation: Up})
+	l.pump()
+	if l.focused() != l.b {
+		t.Errorf("focus moved to %v with nothing above b", l.focused())
+	}
+}
+
+func TestHandleFocusRequestMsgMovesSpatially(t *testing.T) {
+	l := newTestLayout(t)
+	grant := l.right.HandleFocusRequestMsg(FocusRequestMsg{RequestedPath: l.b.GetPath(), Relation: Down})
+	if grant == nil || !IsSamePath(grant.RoutePath.Path, l.c.GetPath()) {
+		t.Errorf("got %+v, want a grant for c", grant)
+	}
+	if grant := l.right.HandleFocusRequestMsg(FocusRequestMsg{RequestedPath: l.b.GetPath(), Relation: Left}); grant != nil {
+		t.Errorf("got %+v for a outside the list", grant)
+	}
+}
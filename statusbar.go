@@ -0,0 +1,205 @@
+package peanutbutter
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+type StatusBarSegment int
+
+const (
+	StatusBarLeft StatusBarSegment = iota
+	StatusBarCenter
+	StatusBarRight
+)
+
+// StatusBar is a one line leaf model with left, center and right
+// segments. Given to the top level panel with WithStatusBar, it is
+// fed with the contextual help of the focused panel and the short
+// help texts of the focus path. When the line is too narrow, the
+// center segment is truncated first, then the right and then the left
+type StatusBar struct {
+	segments          [3]string
+	HelpSegment       StatusBarSegment // where the contextual help goes
+	ShortcutsSegment  StatusBarSegment // where the short help texts go
+	ShortcutSeparator string
+	width             int
+	height            int
+	redraw            bool
+	Style             StatusBarStyle
+}
+
+type StatusBarStyle struct {
+	Bar    lipgloss.Style
+	Left   lipgloss.Style
+	Center lipgloss.Style
+	Right  lipgloss.Style
+}
+
+var DefaultStatusBarStyle = StatusBarStyle{
+	Bar:    lipgloss.NewStyle(),
+	Left:   Fg(Plt.Text()),
+	Center: Fg(Plt.Overlay1()),
+	Right:  Fg(Plt.Overlay1()),
+}
+
+var _ ILeafModel = &StatusBar{}
+var _ ILeafModelWithView = &StatusBar{}
+
+func NewStatusBar() *StatusBar {
+	return &StatusBar{
+		HelpSegment:       StatusBarLeft,
+		ShortcutsSegment:  StatusBarRight,
+		ShortcutSeparator: " • ",
+		redraw:            true,
+		Style:             DefaultStatusBarStyle,
+	}
+}
+
+// WithStatusBar makes the top level panel keep the status bar up
+// to date. The status bar still has to be placed in the layout,
+// e.g. wrapped in a ShortCutPanel
+func WithStatusBar(statusBar *StatusBar) TopLevelListPanelOption {
+	return func(m *TopLevelListPanel) {
+		m.statusBar = statusBar
+	}
+}
+
+func (s *StatusBar) SetSegment(segment StatusBarSegment, text string) {
+	if s.segments[segment] != text {
+		s.segments[segment] = text
+		s.redraw = true
+	}
+}
+
+func (s *StatusBar) GetSegment(segment StatusBarSegment) string {
+	return s.segments[segment]
+}
+
+// SetStatus sets the contextual help and the short help texts
+func (s *StatusBar) SetStatus(contextualHelp string, shortHelpTexts []string) {
+	s.SetSegment(s.HelpSegment, contextualHelp)
+	s.SetSegment(s.ShortcutsSegment, strings.Join(shortHelpTexts, s.ShortcutSeparator))
+}
+
+func (s *StatusBar) Init() tea.Cmd {
+	return nil
+}
+
+func (s *StatusBar) Update(msg Msg) tea.Cmd {
+	if msg, ok := msg.(ResizeMsg); ok {
+		s.width = msg.Width
+		s.height = msg.Height
+		s.redraw = true
+	}
+	return nil
+}
+
+func (s *StatusBar) NeedsRedraw() bool {
+	return s.redraw
+}
+
+// fitSegments truncates the segments so that they fit in width
+// with a space between non-empty neighbours
+func fitSegments(segments [3]string, width int) [3]string {
+	widths := [3]int{}
+	for i, segment := range segments {
+		widths[i] = runewidth.StringWidth(segment)
+	}
+	total := func() int {
+		sum, count := 0, 0
+		for _, w := range widths {
+			if w > 0 {
+				sum += w
+				count++
+			}
+		}
+		return sum + max(count-1, 0)
+	}
+	for _, i := range []StatusBarSegment{StatusBarCenter, StatusBarRight, StatusBarLeft} {
+		if excess := total() - width; excess > 0 {
+			widths[i] = max(widths[i]-excess, 0)
+			if widths[i] == 0 {
+				segments[i] = ""
+				continue
+			}
+			segments[i] = runewidth.Truncate(segments[i], widths[i], "…")
+			widths[i] = runewidth.StringWidth(segments[i])
+		}
+	}
+	return segments
+}
+
+func (s *StatusBar) View() string {
+	s.redraw = false
+	if s.width <= 0 || s.height <= 0 {
+		return ""
+	}
+	segments := fitSegments(s.segments, s.width)
+	left := runewidth.StringWidth(segments[StatusBarLeft])
+	center := runewidth.StringWidth(segments[StatusBarCenter])
+	right := runewidth.StringWidth(segments[StatusBarRight])
+
+	// center the middle segment on the line if there is room,
+	// otherwise put it right after the left segment
+	centerStart := (s.width - center) / 2
+	if centerStart < left+1 || centerStart+center+1 > s.width-right {
+		centerStart = left
+		if left > 0 && center > 0 {
+			centerStart++
+		}
+	}
+	rightStart := s.width - right
+	line := s.Style.Left.Render(segments[StatusBarLeft]) +
+		strings.Repeat(" ", centerStart-left) +
+		s.Style.Center.Render(segments[StatusBarCenter]) +
+		strings.Repeat(" ", max(rightStart-centerStart-center, 0)) +
+		s.Style.Right.Render(segments[StatusBarRight])
+	lines := []string{s.Style.Bar.Render(line)}
+	for len(lines) < s.height {
+		lines = append(lines, strings.Repeat(" ", s.width))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// statusBarAffectedBy returns true for the messages that can change
+// the focus, the active key bindings or the contextual help. Besides
+// the messages of the framework this includes every message that
+// reaches a leaf model, since the model may push a key layer or
+// change its bindings in response
+func statusBarAffectedBy(msg Msg) bool {
+	switch msg.(type) {
+	case KeyMsg, PasteMsg, MouseMsg, keySequenceTimeoutMsg,
+		AutoRoutedMsg, BroadcastMsg,
+		FocusRequestMsg, FocusGrantMsg, FocusRevokeMsg,
+		PushGlobalKeyLayerMsg, PopGlobalKeyLayerMsg, SetContextValueMsg,
+		ContextualHelpTextMsg, ShowOverlayMsg, HideOverlayMsg,
+		SelectTabIndexMsg, SelectedTabIndexMsg, TabAddedMsg, TabRemovedMsg, TabMovedMsg,
+		StartWorkflowMsg, AbandonWorkflowMsg, WorkflowChangedMsg:
+		return true
+	}
+	return false
+}
+
+// updateStatusBar feeds the status bar with the contextual help of
+// the focused panel and the short help texts of the focus path,
+// the most deeply nested panel first, each preceded by its mode
+func (m *TopLevelListPanel) updateStatusBar() {
+	if m.statusBar == nil {
+		return
+	}
+	ctx := m.KeyContext()
+	shortHelpTexts := m.globalKeyLayers.ShortHelpTexts(nil, &ctx)
+	focusPath := FocusPath(m.ListPanel)
+	for i := len(focusPath) - 1; i >= 0; i-- {
+		shortHelpTexts = append(shortHelpTexts, panelShortHelpTexts(focusPath[i], &ctx)...)
+	}
+	contextualHelp := ""
+	if len(focusPath) > 0 {
+		contextualHelp = m.contextualHelp[focusPath[len(focusPath)-1]]
+	}
+	m.statusBar.SetStatus(contextualHelp, shortHelpTexts)
+}
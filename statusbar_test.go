@@ -0,0 +1,116 @@
+package peanutbutter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFitSegments(t *testing.T) {
+	segments := [3]string{"left", "center", "right"}
+	if got := fitSegments(segments, 80); got != segments {
+		t.Errorf("segments that fit became %q", got)
+	}
+	// the center segment is truncated first
+	if got := fitSegments(segments, 15); got != [3]string{"left", "cen…", "right"} {
+		t.Errorf("width 15 gives %q", got)
+	}
+	// then the right one
+	if got := fitSegments(segments, 8); got != [3]string{"left", "", "ri…"} {
+		t.Errorf("width 8 gives %q", got)
+	}
+}
+
+func TestStatusBarView(t *testing.T) {
+	statusBar := NewStatusBar()
+	statusBar.Update(ResizeMsg{Width: 20, Height: 1})
+	statusBar.SetSegment(StatusBarLeft, "L")
+	statusBar.SetSegment(StatusBarCenter, "C")
+	statusBar.SetSegment(StatusBarRight, "R")
+	if view := statusBar.View(); view != "L        C         R" {
+		t.Errorf("view is %q", view)
+	}
+
+	statusBar.SetStatus("help", []string{"a", "b"})
+	if right := statusBar.GetSegment(StatusBarRight); right != "a • b" {
+		t.Errorf("short help texts are %q", right)
+	}
+}
+
+func newStatusBarLayout(t *testing.T) (*testLayout, *StatusBar) {
+	t.Helper()
+	statusBar := NewStatusBar()
+	l := newTestLayout(t, WithStatusBar(statusBar))
+	for _, panel := range []*ShortCutPanel{l.a, l.c, l.d} {
+		name := panel.GetName()
+		panel.AddKeyBinding(testKeyBinding("x", noop, WithShortHelp("x in "+name)))
+		panel.ContextualHelp = "help for " + name
+	}
+	return l, statusBar
+}
+
+func TestStatusBarFollowsFocus(t *testing.T) {
+	l, statusBar := newStatusBarLayout(t)
+	l.focus(l.a)
+	if help := statusBar.GetSegment(StatusBarLeft); help != "help for a" {
+		t.Errorf("contextual help is %q", help)
+	}
+	if shortcuts := statusBar.GetSegment(StatusBarRight); !strings.Contains(shortcuts, "x in a") {
+		t.Errorf("short help texts are %q", shortcuts)
+	}
+
+	l.focus(l.b)
+	if help := statusBar.GetSegment(StatusBarLeft); help != "" {
+		t.Errorf("b has contextual help %q", help)
+	}
+}
+
+// TestStatusBarShowsPanelMode checks that the mode of a panel on the
+// focus path is shown, and that the status bar catches up with a
+// layer pushed by a model in response to the result of its command
+func TestStatusBarShowsPanelMode(t *testing.T) {
+	l, statusBar := newStatusBarLayout(t)
+	l.focus(l.a)
+	l.a.PushKeyLayer(NewKeyLayer("INSERT", false, testKeyBinding("Esc", noop, WithShortHelp("normal"))))
+	l.top.HandleMessage(AutoRoutedMsg{Msg: "done", RoutePath: RoutePath{Path: l.a.GetPath()}})
+	shortcuts := statusBar.GetSegment(StatusBarRight)
+	if !strings.HasPrefix(shortcuts, "-- INSERT --") || !strings.Contains(shortcuts, "Esc: normal") {
+		t.Errorf("short help texts are %q", shortcuts)
+	}
+	if strings.Contains(shortcuts, "x in a") {
+		t.Errorf("bindings below the layer shown in %q", shortcuts)
+	}
+}
+
+func TestStatusBarHelpFollowsMovedTab(t *testing.T) {
+	l, statusBar := newStatusBarLayout(t)
+	l.focus(l.c)
+	l.cmds <- l.tabs.MoveTab(0, 1)
+	l.pump()
+	if help := statusBar.GetSegment(StatusBarLeft); help != "help for c" {
+		t.Errorf("contextual help of moved c is %q", help)
+	}
+
+	l.cmds <- l.tabs.RemoveTab(1)
+	l.pump()
+	if l.focused() != l.d {
+		t.Fatalf("focus on %v after removing c", l.focused().GetName())
+	}
+	if help := statusBar.GetSegment(StatusBarLeft); help != "help for d" {
+		t.Errorf("contextual help of d is %q", help)
+	}
+	if _, ok := l.top.contextualHelp[l.c]; ok {
+		t.Error("help of removed c kept")
+	}
+}
+
+func TestStatusBarAffectedBy(t *testing.T) {
+	if !statusBarAffectedBy(FocusGrantMsg{}) {
+		t.Error("focus grants do not update the status bar")
+	}
+	if !statusBarAffectedBy(AutoRoutedMsg{}) || !statusBarAffectedBy(BroadcastMsg{}) {
+		t.Error("routed command results do not update the status bar")
+	}
+	if statusBarAffectedBy(ResizeMsg{}) {
+		t.Error("resizing updates the status bar")
+	}
+}
@@ -12,8 +12,17 @@ type PanelStyle struct {
 }
 
 type TitleStyle struct {
-	FocusedTitle   lipgloss.Style
-	UnfocusedTitle lipgloss.Style
+	FocusedTitle     lipgloss.Style
+	UnfocusedTitle   lipgloss.Style
+	FocusWithinTitle *lipgloss.Style // ListPanels with focus-within, while a descendant is focused; FocusedTitle if nil
+
+	// Tab bar of ZStacked ListPanels
+	TabSeparator         string // drawn between tabs; if empty, tabs are padded with spaces
+	TabSeparatorStyle    lipgloss.Style
+	TabBadgeStyle        lipgloss.Style
+	ScrollLeftIndicator  string // shown when tabs are cut off on the left, "«" if empty
+	ScrollRightIndicator string // shown when tabs are cut off on the right, "»" if empty
+	ScrollIndicatorStyle lipgloss.Style
 }
 
 func (t *TitleStyle) RenderTitle(title string, focus bool) string {
@@ -41,6 +50,8 @@ func FgBg(fg catppuccin.Color, bg catppuccin.Color) lipgloss.Style {
 	return lipgloss.NewStyle().Foreground(LgColor(fg)).Background(LgColor(bg))
 }
 
+var defaultFocusWithinTitle = lipgloss.NewStyle().Foreground(LgColor(Plt.Lavender())).Padding(0, 0)
+
 var DefaultPanelConfig = ShortCutPanelConfig{
 	PanelStyle: PanelStyle{
 		FocusedBorder: lipgloss.NewStyle().
@@ -52,8 +63,9 @@ var DefaultPanelConfig = ShortCutPanelConfig{
 			BorderForeground(LgColor(Plt.Text())),
 	},
 	TitleStyle: TitleStyle{
-		FocusedTitle:   lipgloss.NewStyle().Bold(true).Padding(0, 0),
-		UnfocusedTitle: lipgloss.NewStyle().Padding(0, 0),
+		FocusedTitle:     lipgloss.NewStyle().Bold(true).Padding(0, 0),
+		UnfocusedTitle:   lipgloss.NewStyle().Padding(0, 0),
+		FocusWithinTitle: &defaultFocusWithinTitle,
 	},
 }
 
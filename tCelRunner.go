@@ -9,10 +9,26 @@ import (
 )
 
 type pbRunModel struct {
-	model IRootModel
-	s     tcell.Screen
-	quit  chan struct{}
-	cmds  chan tea.Cmd
+	model    IRootModel
+	s        tcell.Screen
+	quit     chan struct{}
+	cmds     chan tea.Cmd
+	pasting  bool
+	pasteBuf []rune
+}
+
+// pasteRune returns the rune a key event stands for inside
+// a bracketed paste
+func pasteRune(ev *tcell.EventKey) (rune, bool) {
+	switch ev.Key() {
+	case tcell.KeyRune:
+		return ev.Rune(), true
+	case tcell.KeyEnter, tcell.KeyLF:
+		return '\n', true
+	case tcell.KeyTab:
+		return '\t', true
+	}
+	return 0, false
 }
 
 func (t *pbRunModel) update(ev tcell.Event) {
@@ -40,7 +56,25 @@ func (t *pbRunModel) update(ev tcell.Event) {
 			t.s.Show()
 		}
 
+	case *tcell.EventPaste:
+		if ev.Start() {
+			t.pasting = true
+			t.pasteBuf = t.pasteBuf[:0]
+			return
+		}
+		t.pasting = false
+		t.model.Update(PasteMsg{Text: string(t.pasteBuf)})
+		if t.model.Draw() {
+			t.s.Show()
+		}
+
 	case *tcell.EventKey:
+		if t.pasting {
+			if r, ok := pasteRune(ev); ok {
+				t.pasteBuf = append(t.pasteBuf, r)
+			}
+			return
+		}
 		unused := true
 		direction := DownwardPropagation
 		kmsg := KeyMsg{EventKey: ev, Unused: &unused, Direction: &direction}
@@ -49,6 +83,13 @@ func (t *pbRunModel) update(ev tcell.Event) {
 			t.s.Show()
 		}
 
+	case *tcell.EventMouse:
+		x, y := ev.Position()
+		t.model.Update(MouseMsg{EventMouse: ev, X: x, Y: y})
+		if t.model.Draw() {
+			t.s.Show()
+		}
+
 	case *tcell.EventResize:
 		w, h := ev.Size()
 		resizeMsg := ResizeMsg{EventResize: ev, Width: int(w), Height: int(h)}
@@ -93,6 +134,9 @@ func HandleBatchCmds(msg Msg) []tea.Cmd {
 
 func Run(model IRootModel, screen tcell.Screen) {
 
+	screen.EnableMouse(tcell.MouseButtonEvents, tcell.MouseDragEvents)
+	screen.EnablePaste()
+
 	cmds := make(chan tea.Cmd, 100)
 	viewPort := tcellviews.NewViewPort(screen, 0, 0, -1, -1)
 
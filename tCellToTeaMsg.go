@@ -105,3 +105,47 @@ func MapTCellKeyToTeaMsg(ev tcell.EventKey) TcellKeyToTeaMsgResult {
 	}
 	return TcellKeyToTeaMsgResult{Ok: false}
 }
+
+// MapPasteMsg translates a PasteMsg into the tea.KeyMsg that
+// bubbletea produces for a bracketed paste. Leaf models that wrap
+// bubbles components call it on PasteMsg, the way they call
+// MapKeyMsg on KeyMsg
+func MapPasteMsg(msg PasteMsg) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(msg.Text), Paste: true}
+}
+
+var tCellButtonToTeaMouseButton = []struct {
+	button tcell.ButtonMask
+	tea    tea.MouseButton
+}{
+	{tcell.ButtonPrimary, tea.MouseButtonLeft},
+	{tcell.ButtonSecondary, tea.MouseButtonRight},
+	{tcell.ButtonMiddle, tea.MouseButtonMiddle},
+	{tcell.WheelUp, tea.MouseButtonWheelUp},
+	{tcell.WheelDown, tea.MouseButtonWheelDown},
+	{tcell.WheelLeft, tea.MouseButtonWheelLeft},
+	{tcell.WheelRight, tea.MouseButtonWheelRight},
+}
+
+// MapMouseMsg translates a MouseMsg into a tea.MouseMsg
+// tcell does not report releases separately, so a message with no
+// buttons pressed is mapped to a release
+func MapMouseMsg(msg MouseMsg) tea.MouseMsg {
+	mouse := tea.MouseMsg{
+		X:      msg.X,
+		Y:      msg.Y,
+		Shift:  msg.Modifiers()&tcell.ModShift != 0,
+		Alt:    msg.Modifiers()&tcell.ModAlt != 0,
+		Ctrl:   msg.Modifiers()&tcell.ModCtrl != 0,
+		Action: tea.MouseActionRelease,
+		Button: tea.MouseButtonNone,
+	}
+	for _, b := range tCellButtonToTeaMouseButton {
+		if msg.Buttons()&b.button != 0 {
+			mouse.Action = tea.MouseActionPress
+			mouse.Button = b.tea
+			break
+		}
+	}
+	return mouse
+}
@@ -0,0 +1,202 @@
+package peanutbutter
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+type TabBarPosition int
+
+const (
+	TabBarTop TabBarPosition = iota
+	TabBarBottom
+)
+
+type TabBarAlignment int
+
+const (
+	TabBarAlignLeft TabBarAlignment = iota
+	TabBarAlignRight
+)
+
+// WithTabBarPosition draws the tabs of a ZStacked ListPanel
+// on its top or bottom border
+func WithTabBarPosition(position TabBarPosition) ListPanelOption {
+	return func(m *ListPanel) {
+		m.tabBarPosition = position
+	}
+}
+
+// WithTabBarAlignment aligns the tabs of a ZStacked ListPanel
+// with the left or right side of its border
+func WithTabBarAlignment(alignment TabBarAlignment) ListPanelOption {
+	return func(m *ListPanel) {
+		m.tabBarAlignment = alignment
+	}
+}
+
+// tabBarPart is a piece of text drawn on the border with a style
+// A gap only takes up room, leaving the border visible
+type tabBarPart struct {
+	text  string
+	style lipgloss.Style
+	gap   bool
+}
+
+type tabBarItem []tabBarPart
+
+func (item tabBarItem) width() int {
+	width := 0
+	for _, part := range item {
+		width += runewidth.StringWidth(part.text)
+	}
+	return width
+}
+
+func tabBadge(panel IPanel) string {
+	if badged, ok := panel.(IPanelWithTabBadge); ok {
+		return badged.GetTabBadge()
+	}
+	return ""
+}
+
+// tabBarItems returns the label of each tab. The selected tab is
+// put in brackets; without a separator the other tabs are padded
+func (p *ListPanel) tabBarItems() []tabBarItem {
+	items := make([]tabBarItem, len(p.Panels))
+	for i, panel := range p.Panels {
+		selected := i == p.Selected
+		titleStyle := p.titleStyle.UnfocusedTitle
+		if selected {
+			titleStyle = p.titleStyle.FocusedTitle
+			if p.focusState() == focusWithin {
+				titleStyle = p.titleStyle.focusWithinTitle()
+			}
+		}
+		name := panel.GetName()
+		if selected {
+			name = "[" + name
+		}
+		item := tabBarItem{{text: name, style: titleStyle}}
+		if badge := tabBadge(panel); badge != "" {
+			item = append(item, tabBarPart{text: " " + badge, style: p.titleStyle.TabBadgeStyle})
+		}
+		if selected {
+			item = append(item, tabBarPart{text: "]", style: titleStyle})
+		} else if p.titleStyle.TabSeparator == "" {
+			item = append(tabBarItem{{text: " ", gap: true}}, item...)
+			item = append(item, tabBarPart{text: " ", gap: true})
+		}
+		items[i] = item
+	}
+	return items
+}
+
+// tabBarSignature identifies what the tab bar shows, so it can be
+// redrawn when a tab that is not drawn changes its badge
+func (p *ListPanel) tabBarSignature() string {
+	var sb strings.Builder
+	for _, item := range p.tabBarItems() {
+		for _, part := range item {
+			sb.WriteString(part.text)
+		}
+		sb.WriteString("\x00")
+	}
+	return sb.String()
+}
+
+// visibleTabs returns the range of tabs that fits in width along
+// with the selected tab, leaving room for the scroll indicators
+func visibleTabs(widths []int, selected int, separator int, indicators int, width int) (int, int) {
+	total := 0
+	for i, w := range widths {
+		total += w
+		if i > 0 {
+			total += separator
+		}
+	}
+	if total <= width {
+		return 0, len(widths) - 1
+	}
+	width -= indicators
+	first, last := selected, selected
+	used := widths[selected]
+	for {
+		grown := false
+		if last+1 < len(widths) && used+separator+widths[last+1] <= width {
+			last++
+			used += separator + widths[last]
+			grown = true
+		}
+		if first > 0 && used+separator+widths[first-1] <= width {
+			first--
+			used += separator + widths[first]
+			grown = true
+		}
+		if !grown {
+			return first, last
+		}
+	}
+}
+
+func (p *ListPanel) renderTabs() {
+	if len(p.Panels) == 0 {
+		return
+	}
+	items := p.tabBarItems()
+	widths := make([]int, len(items))
+	for i, item := range items {
+		widths[i] = item.width()
+	}
+	separator := tabBarPart{text: p.titleStyle.TabSeparator, style: p.titleStyle.TabSeparatorStyle}
+	indicatorGap := separator
+	if separator.text == "" {
+		indicatorGap = tabBarPart{text: " ", gap: true}
+	}
+	leftIndicator := tabBarPart{text: p.titleStyle.ScrollLeftIndicator, style: p.titleStyle.ScrollIndicatorStyle}
+	if leftIndicator.text == "" {
+		leftIndicator.text = "«"
+	}
+	rightIndicator := tabBarPart{text: p.titleStyle.ScrollRightIndicator, style: p.titleStyle.ScrollIndicatorStyle}
+	if rightIndicator.text == "" {
+		rightIndicator.text = "»"
+	}
+
+	viewWidth, _ := p.view.Size()
+	available := viewWidth - 2*titleOffset
+	separatorWidth := runewidth.StringWidth(separator.text)
+	indicatorsWidth := tabBarItem{leftIndicator, indicatorGap, indicatorGap, rightIndicator}.width()
+	first, last := visibleTabs(widths, p.Selected, separatorWidth, indicatorsWidth, available)
+
+	parts := []tabBarPart{}
+	if first > 0 {
+		parts = append(parts, leftIndicator, indicatorGap)
+	}
+	for i := first; i <= last; i++ {
+		if i > first {
+			parts = append(parts, separator)
+		}
+		parts = append(parts, items[i]...)
+	}
+	if last < len(items)-1 {
+		parts = append(parts, indicatorGap, rightIndicator)
+	}
+
+	width := tabBarItem(parts).width()
+	x := titleOffset
+	if p.tabBarAlignment == TabBarAlignRight {
+		x = max(viewWidth-titleOffset-width, 0)
+	}
+	edge := renderOnTopEdge
+	if p.tabBarPosition == TabBarBottom {
+		edge = renderOnBottomEdge
+	}
+	for _, part := range parts {
+		if part.text != "" && !part.gap {
+			renderTextOnBorder(part.style.Render(part.text), edge, offsetFromLeftSide, x, p.view)
+		}
+		x += runewidth.StringWidth(part.text)
+	}
+}
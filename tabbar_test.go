@@ -0,0 +1,92 @@
+package peanutbutter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVisibleTabs(t *testing.T) {
+	widths := []int{5, 5, 5, 5, 5}
+	for _, test := range []struct {
+		selected, width int
+		first, last     int
+	}{
+		{selected: 0, width: 25, first: 0, last: 4},
+		{selected: 0, width: 14, first: 0, last: 1},
+		{selected: 4, width: 14, first: 3, last: 4},
+		{selected: 2, width: 19, first: 1, last: 3},
+	} {
+		first, last := visibleTabs(widths, test.selected, 0, 4, test.width)
+		if first != test.first || last != test.last {
+			t.Errorf("selected %d in width %d shows %d..%d, want %d..%d",
+				test.selected, test.width, first, last, test.first, test.last)
+		}
+	}
+}
+
+// newTabBarLayout makes tabs, holding c and d, the whole screen
+func newTabBarLayout(t *testing.T, width int, options ...ListPanelOption) *testLayout {
+	t.Helper()
+	l := &testLayout{c: newTestPanel("c"), d: newTestPanel("d")}
+	options = append([]ListPanelOption{WithListPanelName("tabs")}, options...)
+	l.tabs = NewListPanel([]IPanel{l.c, l.d}, Layout{Orientation: ZStacked}, options...)
+	l.top = NewTopLevelListPanel(NewListPanel([]IPanel{l.tabs}, Layout{Orientation: Vertical, Dimensions: []Dimension{{}}}))
+	l.start(t)
+	l.screen.SetSize(width, 6)
+	l.top.HandleMessage(ResizeMsg{Width: width, Height: 6})
+	l.pump()
+	return l
+}
+
+func TestTabBarBadgeAndPadding(t *testing.T) {
+	l := newTabBarLayout(t, 30)
+	l.c.SetTabBadge("3")
+	l.draw()
+	if row := l.row(0); !strings.HasPrefix(row, "┌─[c 3]─d─") {
+		t.Errorf("tab bar is %q", row)
+	}
+}
+
+func TestTabBarSeparator(t *testing.T) {
+	titleStyle := DefaultPanelConfig.TitleStyle
+	titleStyle.TabSeparator = "|"
+	l := newTabBarLayout(t, 30, WithListPanelTitleStyle(titleStyle))
+	l.draw()
+	if row := l.row(0); !strings.HasPrefix(row, "┌─[c]|d─") {
+		t.Errorf("tab bar is %q", row)
+	}
+}
+
+func TestTabBarBottomRight(t *testing.T) {
+	l := newTabBarLayout(t, 30, WithTabBarPosition(TabBarBottom), WithTabBarAlignment(TabBarAlignRight))
+	l.draw()
+	if row := l.row(5); !strings.HasSuffix(row, "─[c]─d──┘") {
+		t.Errorf("bottom border is %q", row)
+	}
+	if row := l.row(0); strings.Contains(row, "[c]") {
+		t.Errorf("tabs also drawn on top: %q", row)
+	}
+}
+
+func TestTabBarScrolls(t *testing.T) {
+	l := &testLayout{}
+	panels := []IPanel{}
+	for _, name := range []string{"one", "two", "three", "four", "five", "six"} {
+		panels = append(panels, newTestPanel(name))
+	}
+	l.tabs = NewListPanel(panels, Layout{Orientation: ZStacked}, WithListPanelName("tabs"))
+	l.top = NewTopLevelListPanel(NewListPanel([]IPanel{l.tabs}, Layout{Orientation: Vertical, Dimensions: []Dimension{{}}}))
+	l.start(t)
+	l.screen.SetSize(24, 6)
+	l.top.HandleMessage(ResizeMsg{Width: 24, Height: 6})
+	l.top.HandleMessage(SelectTabIndexMsg{TabName: "five", ListPanelName: "tabs"})
+	l.pump()
+	l.draw()
+	row := l.row(0)
+	if !strings.Contains(row, "«") || !strings.Contains(row, "[five]") {
+		t.Errorf("tab bar is %q", row)
+	}
+	if strings.Contains(row, "one") {
+		t.Errorf("first tab still shown: %q", row)
+	}
+}
@@ -0,0 +1,55 @@
+package peanutbutter
+
+import "testing"
+
+// newTabFocusLayout puts a above b in the first tab of tabs, c in
+// the second tab, and d next to tabs
+func newTabFocusLayout(t *testing.T) *testLayout {
+	t.Helper()
+	l := &testLayout{
+		a: newTestPanel("a"),
+		b: newTestPanel("b"),
+		c: newTestPanel("c"),
+		d: newTestPanel("d"),
+	}
+	first := NewListPanel([]IPanel{l.a, l.b}, Layout{Orientation: Vertical, Dimensions: []Dimension{{Ratio: 0.5}, {}}})
+	l.tabs = NewListPanel([]IPanel{first, l.c}, Layout{Orientation: ZStacked}, WithListPanelName("tabs"))
+	root := NewListPanel([]IPanel{l.tabs, l.d}, Layout{Orientation: Horizontal, Dimensions: []Dimension{{Ratio: 0.5}, {}}})
+	l.top = NewTopLevelListPanel(root)
+	l.start(t)
+	return l
+}
+
+func (l *testLayout) selectTab(name string) {
+	l.top.HandleMessage(SelectTabIndexMsg{TabName: name, ListPanelName: "tabs"})
+	l.pump()
+}
+
+func TestSelectTabMovesFocusIntoNewTab(t *testing.T) {
+	l := newTabFocusLayout(t)
+	l.focus(l.a)
+	l.selectTab("c")
+	if l.focused() != l.c {
+		t.Errorf("focus on %v, want c", l.focused().GetName())
+	}
+}
+
+func TestSelectTabKeepsFocusOutsideTabs(t *testing.T) {
+	l := newTabFocusLayout(t)
+	l.focus(l.d)
+	l.selectTab("c")
+	if l.focused() != l.d {
+		t.Errorf("focus on %v, want d", l.focused().GetName())
+	}
+}
+
+func TestTabRemembersFocus(t *testing.T) {
+	l := newTabFocusLayout(t)
+	l.focus(l.b)
+	l.selectTab("c")
+	l.top.HandleMessage(SelectTabIndexMsg{Index: 0, ListPanelName: "tabs"})
+	l.pump()
+	if l.focused() != l.b {
+		t.Errorf("focus on %v, want b", l.focused().GetName())
+	}
+}
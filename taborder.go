@@ -0,0 +1,138 @@
+package peanutbutter
+
+import (
+	"sort"
+)
+
+type tabOrderConfig struct {
+	skip      map[IPanel]bool
+	tabIndex  map[IPanel]int
+	noLooping bool
+}
+
+type TabOrderOption func(*tabOrderConfig)
+
+// WithTabOrderSkip leaves panels, and everything under them, out of the tab order
+func WithTabOrderSkip(panels ...IPanel) TabOrderOption {
+	return func(c *tabOrderConfig) {
+		for _, panel := range panels {
+			c.skip[panel] = true
+		}
+	}
+}
+
+// WithTabIndex moves a panel to the front of the tab order. Panels
+// with a tab index are visited first, lowest index first, followed
+// by the remaining panels in reading order. Panels with the same
+// index are visited in reading order
+func WithTabIndex(panel IPanel, index int) TabOrderOption {
+	return func(c *tabOrderConfig) {
+		c.tabIndex[panel] = index
+	}
+}
+
+// WithTabOrderLoop sets whether moving past the last panel
+// continues with the first one. It does by default
+func WithTabOrderLoop(loop bool) TabOrderOption {
+	return func(c *tabOrderConfig) {
+		c.noLooping = !loop
+	}
+}
+
+// TabOrderMovementMap derives a movement map from the panel tree under
+// root in reading order: a Horizontal or Vertical ListPanel visits its
+// children one after the other, while a ZStacked ListPanel only visits
+// its visible tab
+func TabOrderMovementMap(root IPanel, options ...TabOrderOption) IMovementNode {
+	config := &tabOrderConfig{
+		skip:     make(map[IPanel]bool),
+		tabIndex: make(map[IPanel]int),
+	}
+	for _, option := range options {
+		option(config)
+	}
+
+	chains := []IMovementNode{}
+	if len(config.tabIndex) > 0 {
+		// collected in reading order, so that panels with the same
+		// tab index keep it; skipped subtrees are left out
+		indexed := []IPanel{}
+		WalkPanels(root, func(panel IPanel) bool {
+			if config.skip[panel] {
+				return false
+			}
+			if _, ok := config.tabIndex[panel]; ok {
+				indexed = append(indexed, panel)
+			}
+			return true
+		})
+		sort.SliceStable(indexed, func(i, j int) bool {
+			return config.tabIndex[indexed[i]] < config.tabIndex[indexed[j]]
+		})
+		if len(indexed) > 0 {
+			chains = append(chains, NewPanelSequence(indexed...))
+		}
+	}
+	if node := tabOrderNode(root, config); node != nil {
+		chains = append(chains, node)
+	}
+
+	if config.noLooping {
+		return NewSequentialSelectorNode(chains...)
+	}
+	return NewLoopAroundTabMap(chains...)
+}
+
+func tabOrderNode(panel IPanel, config *tabOrderConfig) IMovementNode {
+	if config.skip[panel] {
+		return nil
+	}
+	if _, ok := config.tabIndex[panel]; ok {
+		return nil
+	}
+	if IsLeafPanel(panel) {
+		return NewPanelSequence(panel)
+	}
+
+	chains := []IMovementNode{}
+	for _, child := range panel.(IPanelWithChildren).GetChildren() {
+		if node := tabOrderNode(child, config); node != nil {
+			chains = append(chains, node)
+		}
+	}
+	if len(chains) == 0 {
+		return nil
+	}
+	if listPanel, ok := panel.(*ListPanel); ok && listPanel.Layout.Orientation == ZStacked {
+		return NewVisibleSelectorNode(chains...)
+	}
+	return NewSequentialSelectorNode(chains...)
+}
+
+// AddTabOrderKeyBindings adds next and previous bindings, usually
+// Tab and Shift+Tab, to container that move the focus in the tab order
+// of TabOrderMovementMap. The map is derived again on every key press,
+// so it follows changes to the layout such as added or removed tabs
+func AddTabOrderKeyBindings(container IPanel, next KeyBinding, prev KeyBinding, options ...TabOrderOption) {
+	nextKb, prevKb := next, prev
+	nextKb.Func = movementFunc(container, func(focused IPanel) IPanel {
+		return TabOrderMovementMap(container, options...).Next(focused)
+	})
+	prevKb.Func = movementFunc(container, func(focused IPanel) IPanel {
+		return TabOrderMovementMap(container, options...).Previous(focused)
+	})
+	container.AddKeyBinding(&nextKb)
+	container.AddKeyBinding(&prevKb)
+}
+
+// WithTabOrder moves the focus with Tab and Shift+Tab in the
+// tab order of the whole layout, see AddTabOrderKeyBindings
+func WithTabOrder(options ...TabOrderOption) TopLevelListPanelOption {
+	return func(m *TopLevelListPanel) {
+		next := KeyTabBinding
+		next.LongHelp = "Focus the next panel"
+		prev := KeyShiftTabBinding
+		prev.LongHelp = "Focus the previous panel"
+		AddTabOrderKeyBindings(m.ListPanel, next, prev, options...)
+	}
+}
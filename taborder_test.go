@@ -0,0 +1,97 @@
+package peanutbutter
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// tabOrder follows the tab order of root from its first panel
+// until it comes back around or ends
+func tabOrder(root IMovementNode) []string {
+	names := []string{}
+	first := root.First()
+	for panel := first; panel != nil && len(names) < 10; {
+		names = append(names, panel.GetName())
+		if panel = root.Next(panel); panel == first {
+			break
+		}
+	}
+	return names
+}
+
+func checkTabOrder(t *testing.T, root IMovementNode, want ...string) {
+	t.Helper()
+	got := tabOrder(root)
+	if len(got) != len(want) {
+		t.Errorf("tab order %v, want %v", got, want)
+		return
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("tab order %v, want %v", got, want)
+			return
+		}
+	}
+}
+
+func TestTabOrderReadingOrder(t *testing.T) {
+	l := newTestLayout(t)
+	root := TabOrderMovementMap(l.top.ListPanel)
+	// d is in a hidden tab
+	checkTabOrder(t, root, "a", "b", "c")
+	if previous := root.Previous(l.a); previous != l.c {
+		t.Errorf("previous of a is %v, want c", panelName(previous))
+	}
+
+	l.top.HandleMessage(SelectTabIndexMsg{TabName: "d", ListPanelName: "tabs"})
+	l.pump()
+	checkTabOrder(t, TabOrderMovementMap(l.top.ListPanel), "a", "b", "d")
+}
+
+func TestTabIndex(t *testing.T) {
+	l := newTestLayout(t)
+	checkTabOrder(t, TabOrderMovementMap(l.top.ListPanel, WithTabIndex(l.c, 1), WithTabIndex(l.b, 2)), "c", "b", "a")
+
+	// equal indices keep reading order, whatever the order of the options
+	for i := 0; i < 10; i++ {
+		checkTabOrder(t, TabOrderMovementMap(l.top.ListPanel, WithTabIndex(l.c, 1), WithTabIndex(l.b, 1)), "b", "c", "a")
+	}
+}
+
+func TestTabOrderSkip(t *testing.T) {
+	l := newTestLayout(t)
+	checkTabOrder(t, TabOrderMovementMap(l.top.ListPanel, WithTabOrderSkip(l.b)), "a", "c")
+	// a tab index does not bring back a panel of a skipped subtree
+	checkTabOrder(t, TabOrderMovementMap(l.top.ListPanel, WithTabOrderSkip(l.right), WithTabIndex(l.c, 0)), "a")
+}
+
+func TestTabOrderNoLooping(t *testing.T) {
+	l := newTestLayout(t)
+	root := TabOrderMovementMap(l.top.ListPanel, WithTabOrderLoop(false))
+	if next := root.Next(l.c); next != nil {
+		t.Errorf("next of c is %v without looping", panelName(next))
+	}
+	if previous := root.Previous(l.a); previous != nil {
+		t.Errorf("previous of a is %v without looping", panelName(previous))
+	}
+}
+
+func TestWithTabOrder(t *testing.T) {
+	l := newTestLayout(t, WithTabOrder())
+	l.passKeys()
+	l.focus(l.a)
+	l.press(tcell.KeyTab, 0, 0)
+	l.press(tcell.KeyTab, 0, 0)
+	if l.focused() != l.c {
+		t.Errorf("focus on %v, want c", panelName(l.focused()))
+	}
+	l.press(tcell.KeyTab, 0, 0)
+	if l.focused() != l.a {
+		t.Errorf("focus on %v, want a", panelName(l.focused()))
+	}
+	l.press(tcell.KeyBacktab, 0, 0)
+	if l.focused() != l.c {
+		t.Errorf("focus on %v after Shift+Tab, want c", panelName(l.focused()))
+	}
+}
@@ -0,0 +1,175 @@
+package peanutbutter
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	tcellviews "github.com/gdamore/tcell/v2/views"
+)
+
+// TabAddedMsg is broadcast when a tab is added to a ZStacked ListPanel
+// TabNames is the new order of the tabs
+type TabAddedMsg struct {
+	Index         int
+	TabName       string
+	ListPanelName string
+	TabNames      []string
+}
+
+// TabRemovedMsg is broadcast when a tab is removed from a ZStacked ListPanel
+type TabRemovedMsg struct {
+	Index         int
+	TabName       string
+	ListPanelName string
+	TabNames      []string
+}
+
+// TabMovedMsg is broadcast when a tab of a ZStacked ListPanel is moved
+type TabMovedMsg struct {
+	From          int
+	To            int
+	TabName       string
+	ListPanelName string
+	TabNames      []string
+}
+
+// TabNames returns the names of the tabs in order
+func (m *ListPanel) TabNames() []string {
+	names := make([]string, len(m.Panels))
+	for i, panel := range m.Panels {
+		names[i] = panel.GetName()
+	}
+	return names
+}
+
+// resizeTab gives panel the inner size of a ZStacked list
+func (m *ListPanel) resizeTab(panel IPanel) {
+	if m.view == nil {
+		return
+	}
+	width, height := m.view.Size()
+	startX, startY, horz, vert := GetStylingMargins(&m.panelStyle)
+	panel.HandleMessage(ResizeMsg{X: startX, Y: startY, Width: width - horz, Height: height - vert})
+}
+
+// AddTab inserts panel as a tab at index, or after the last tab
+// if index is out of range. The panel is initialized, sized to the
+// list and given the keymap applied at Init; the selected tab stays
+// selected
+func (m *ListPanel) AddTab(panel IPanel, index int) tea.Cmd {
+	if m.Layout.Orientation != ZStacked {
+		DebugPrintf("ListPanel %v: AddTab needs a ZStacked layout\n", m.path)
+		return nil
+	}
+	if index < 0 || index > len(m.Panels) {
+		index = len(m.Panels)
+	}
+	m.Panels = append(m.Panels, nil)
+	copy(m.Panels[index+1:], m.Panels[index:])
+	m.Panels[index] = panel
+	if index <= m.Selected && len(m.Panels) > 1 {
+		m.Selected++
+	}
+
+	if m.view != nil {
+		panel.SetView(tcellviews.NewViewPort(m.view, 0, 0, -1, -1))
+	}
+	m.SetPath(m.path)
+	if m.cmds != nil {
+		panel.Init(m.cmds)
+	}
+	if m.keyMap != nil {
+		if _, err := m.keyMap.ApplyToKeyBindings(panelKeyBindings(panel)); err != nil {
+			DebugPrintf("ListPanel %v: %v\n", m.path, err)
+		}
+		keepKeyMapConfig(panel, m.keyMap)
+	}
+	m.resizeTab(panel)
+	m.updateTabHidden()
+	m.updateNumberedTabKeyBindings()
+	m.redraw = true
+
+	addedMsg := TabAddedMsg{Index: index, TabName: panel.GetName(), ListPanelName: m.Name, TabNames: m.TabNames()}
+	return func() tea.Msg {
+		return addedMsg
+	}
+}
+
+// RemoveTab removes the tab at index. If it was selected, the tab
+// that takes its place is selected, and if it was focused, the focus
+// moves into that tab
+func (m *ListPanel) RemoveTab(index int) tea.Cmd {
+	if m.Layout.Orientation != ZStacked || index < 0 || index >= len(m.Panels) {
+		DebugPrintf("ListPanel %v: cannot remove tab %v\n", m.path, index)
+		return nil
+	}
+	removed := m.Panels[index]
+	wasFocused := removed.IsFocused()
+	if wasFocused {
+		removed.HandleMessage(FocusRevokeMsg{})
+	}
+	m.Panels = append(m.Panels[:index], m.Panels[index+1:]...)
+	delete(m.tabFocus, removed)
+
+	cmds := []tea.Cmd{}
+	removedMsg := TabRemovedMsg{Index: index, TabName: removed.GetName(), ListPanelName: m.Name, TabNames: m.TabNames()}
+	cmds = append(cmds, func() tea.Msg {
+		return removedMsg
+	})
+	switch {
+	case index < m.Selected:
+		m.Selected--
+	case index == m.Selected:
+		m.Selected = min(m.Selected, max(len(m.Panels)-1, 0))
+		if len(m.Panels) > 0 {
+			selectedMsg := SelectedTabIndexMsg{Index: m.Selected, TabName: m.Panels[m.Selected].GetName(), ListPanelName: m.Name}
+			cmds = append(cmds, func() tea.Msg {
+				return selectedMsg
+			})
+		}
+	}
+	m.SetPath(m.path)
+	m.updateTabHidden()
+	m.updateNumberedTabKeyBindings()
+	m.redraw = true
+
+	if wasFocused {
+		if len(m.Panels) > 0 {
+			cmds = append(cmds, m.restoreTabFocusCmd(m.Selected))
+		} else {
+			focusRequestMsg := FocusRequestMsg{RequestedPath: m.GetPath(), Relation: Self}
+			cmds = append(cmds, func() tea.Msg {
+				return focusRequestMsg
+			})
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// MoveTab moves the tab at from so that it ends up at index to
+// The selected tab stays selected
+func (m *ListPanel) MoveTab(from int, to int) tea.Cmd {
+	if m.Layout.Orientation != ZStacked || from < 0 || from >= len(m.Panels) || to < 0 || to >= len(m.Panels) || from == to {
+		DebugPrintf("ListPanel %v: cannot move tab %v to %v\n", m.path, from, to)
+		return nil
+	}
+	selected := m.Panels[m.Selected]
+	moved := m.Panels[from]
+	if from < to {
+		copy(m.Panels[from:to], m.Panels[from+1:to+1])
+	} else {
+		copy(m.Panels[to+1:from+1], m.Panels[to:from])
+	}
+	m.Panels[to] = moved
+	for i, panel := range m.Panels {
+		if panel == selected {
+			m.Selected = i
+		}
+	}
+	m.SetPath(m.path)
+	m.updateNumberedTabKeyBindings()
+	m.redraw = true
+
+	movedMsg := TabMovedMsg{From: from, To: to, TabName: moved.GetName(), ListPanelName: m.Name, TabNames: m.TabNames()}
+	return func() tea.Msg {
+		return movedMsg
+	}
+}
@@ -0,0 +1,118 @@
+package peanutbutter
+
+import (
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// runTabCmd hands the messages of a tab command to the top level
+// panel and returns them
+func (l *testLayout) runTabCmd(cmd tea.Cmd) []tea.Msg {
+	l.cmds <- cmd
+	return l.pump()
+}
+
+func findMsg[T any](msgs []tea.Msg) (T, bool) {
+	for _, msg := range msgs {
+		if found, ok := msg.(T); ok {
+			return found, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+func TestAddTab(t *testing.T) {
+	l := newTestLayout(t)
+	l.top.HandleMessage(SelectTabIndexMsg{Index: 1, ListPanelName: "tabs"})
+	l.pump()
+	e := newTestPanel("e")
+	msgs := l.runTabCmd(l.tabs.AddTab(e, 0))
+
+	if names := l.tabs.TabNames(); !slices.Equal(names, []string{"e", "c", "d"}) {
+		t.Errorf("tabs are %v", names)
+	}
+	if l.tabs.GetSelected() != l.d {
+		t.Errorf("%v selected after adding a tab", l.tabs.GetSelected().GetName())
+	}
+	if !e.IsInHiddenTab() || !slices.Equal(e.GetPath(), append(l.tabs.GetPath(), 0)) {
+		t.Errorf("added tab hidden %v at %v", e.IsInHiddenTab(), e.GetPath())
+	}
+	addedMsg, ok := findMsg[TabAddedMsg](msgs)
+	if !ok || addedMsg.Index != 0 || addedMsg.TabName != "e" || addedMsg.ListPanelName != "tabs" || len(addedMsg.TabNames) != 3 {
+		t.Errorf("broadcast %+v", addedMsg)
+	}
+
+	// out of range indices append
+	l.runTabCmd(l.tabs.AddTab(newTestPanel("f"), 10))
+	if names := l.tabs.TabNames(); names[len(names)-1] != "f" {
+		t.Errorf("tabs are %v", names)
+	}
+}
+
+func TestAddTabAppliesKeyMap(t *testing.T) {
+	l := newTestLayout(t, WithKeyMapConfig(KeyMapConfig{"save": {"Alt+s"}}))
+	e := newTestPanel("e")
+	save := NewKeyBinding(WithActionID("save"), WithKeys("Ctrl+S"), WithEnabled(true))
+	e.AddKeyBinding(save)
+	l.runTabCmd(l.tabs.AddTab(e, -1))
+	if got := save.renderKeys(); got != "Alt+s" {
+		t.Errorf("save of added tab bound to %q", got)
+	}
+}
+
+func TestRemoveFocusedTab(t *testing.T) {
+	l := newTestLayout(t)
+	l.focus(l.c)
+	msgs := l.runTabCmd(l.tabs.RemoveTab(0))
+
+	if names := l.tabs.TabNames(); !slices.Equal(names, []string{"d"}) {
+		t.Errorf("tabs are %v", names)
+	}
+	if l.focused() != l.d || l.d.IsInHiddenTab() {
+		t.Errorf("focus on %v after removing the focused tab", l.focused().GetName())
+	}
+	if removedMsg, ok := findMsg[TabRemovedMsg](msgs); !ok || removedMsg.TabName != "c" || len(removedMsg.TabNames) != 1 {
+		t.Errorf("broadcast %+v", removedMsg)
+	}
+	if _, ok := findMsg[SelectedTabIndexMsg](msgs); !ok {
+		t.Error("no SelectedTabIndexMsg for the tab taking its place")
+	}
+
+	// removing the last tab moves the focus to the list itself
+	l.runTabCmd(l.tabs.RemoveTab(0))
+	if len(l.tabs.Panels) != 0 || l.focused() != l.tabs {
+		t.Errorf("%d tabs left, focus on %v", len(l.tabs.Panels), l.focused())
+	}
+	if cmd := l.tabs.RemoveTab(0); cmd != nil {
+		t.Error("removing from an empty list did something")
+	}
+}
+
+func TestMoveTab(t *testing.T) {
+	l := newTestLayout(t)
+	l.runTabCmd(l.tabs.AddTab(newTestPanel("e"), -1))
+	msgs := l.runTabCmd(l.tabs.MoveTab(0, 2))
+
+	if names := l.tabs.TabNames(); !slices.Equal(names, []string{"d", "e", "c"}) {
+		t.Errorf("tabs are %v", names)
+	}
+	if l.tabs.GetSelected() != l.c || !slices.Equal(l.c.GetPath(), append(l.tabs.GetPath(), 2)) {
+		t.Errorf("%v selected, c at %v", l.tabs.GetSelected().GetName(), l.c.GetPath())
+	}
+	if movedMsg, ok := findMsg[TabMovedMsg](msgs); !ok || movedMsg.From != 0 || movedMsg.To != 2 || movedMsg.TabName != "c" {
+		t.Errorf("broadcast %+v", movedMsg)
+	}
+	if cmd := l.tabs.MoveTab(1, 1); cmd != nil {
+		t.Error("moving a tab onto itself did something")
+	}
+}
+
+func TestTabsNeedZStackedLayout(t *testing.T) {
+	l := newTestLayout(t)
+	if cmd := l.right.AddTab(newTestPanel("e"), 0); cmd != nil || len(l.right.Panels) != 2 {
+		t.Error("added a tab to a vertical list")
+	}
+}
@@ -0,0 +1,60 @@
+package peanutbutter
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func selectedTabIndexMsgs(msgs []tea.Msg) []SelectedTabIndexMsg {
+	selected := []SelectedTabIndexMsg{}
+	for _, msg := range msgs {
+		if selectedMsg, ok := msg.(SelectedTabIndexMsg); ok {
+			selected = append(selected, selectedMsg)
+		}
+	}
+	return selected
+}
+
+func TestSelectTabByName(t *testing.T) {
+	l := newTestLayout(t)
+	l.top.HandleMessage(SelectTabIndexMsg{TabName: "d", ListPanelName: "tabs"})
+	msgs := l.pump()
+	if l.tabs.GetSelectedIndex() != 1 || !l.c.IsInHiddenTab() || l.d.IsInHiddenTab() {
+		t.Fatalf("tab %v selected", l.tabs.GetSelectedIndex())
+	}
+	selected := selectedTabIndexMsgs(msgs)
+	want := SelectedTabIndexMsg{Index: 1, TabName: "d", ListPanelName: "tabs"}
+	if len(selected) != 1 || selected[0] != want {
+		t.Errorf("broadcast %+v, want %+v", selected, want)
+	}
+}
+
+func TestSelectTabByIndex(t *testing.T) {
+	l := newTestLayout(t)
+	l.top.HandleMessage(SelectTabIndexMsg{Index: 1, ListPanelName: "tabs"})
+	l.pump()
+	l.top.HandleMessage(SelectTabIndexMsg{Index: 0, ListPanelName: "tabs"})
+	msgs := l.pump()
+	if l.tabs.GetSelectedIndex() != 0 {
+		t.Errorf("tab %v selected", l.tabs.GetSelectedIndex())
+	}
+	if selected := selectedTabIndexMsgs(msgs); len(selected) != 1 || selected[0].TabName != "c" {
+		t.Errorf("broadcast %+v", selected)
+	}
+}
+
+func TestSelectTabIgnoresUnknownAndUnchanged(t *testing.T) {
+	l := newTestLayout(t)
+	for _, msg := range []SelectTabIndexMsg{
+		{TabName: "nope", ListPanelName: "tabs"},
+		{Index: 5, ListPanelName: "tabs"},
+		{TabName: "d", ListPanelName: "other"},
+		{Index: 0, ListPanelName: "tabs"},
+	} {
+		l.top.HandleMessage(msg)
+		if selected := selectedTabIndexMsgs(l.pump()); len(selected) != 0 || l.tabs.GetSelectedIndex() != 0 {
+			t.Errorf("%+v selected tab %v and broadcast %+v", msg, l.tabs.GetSelectedIndex(), selected)
+		}
+	}
+}
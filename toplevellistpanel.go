@@ -1,6 +1,8 @@
 package peanutbutter
 
 import (
+	"time"
+
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -9,20 +11,123 @@ import (
 // It handles responding to focus-request messages
 // with focus-grant messages
 // It also initializes the path of all its children
-// And gives all children panels a chance to request
-// focus for key-strokes by passing them a ConsiderForGlobalShortcutMsg
-// before passing a key-stroke as a regular key-stroke message
+// And gives all children panels a chance to act on
+// key-strokes with their global key bindings by passing them a
+// ConsiderForGlobalShortcutMsg before passing a key-stroke
+// as a regular key-stroke message along the focus path
 type TopLevelListPanel struct {
 	*ListPanel
-	cmds chan tea.Cmd
+	cmds            chan tea.Cmd
+	keySequence     KeySequenceState
+	globalKeyLayers KeyLayerStack
+	keyMapConfig    KeyMapConfig
+	keyMapFile      string
+	keyMapErr       error
+	reportConflicts func([]KeyBindingConflict)
+	contextValues   map[string]any
+	overlays        []*overlay
+	nextOverlayID   int
+	overlaysChanged bool
+	whichKeyEnabled bool
+	whichKey        IPanel
+	help            IPanel
+	helpKeyBinding  *KeyBinding
+	statusBar       *StatusBar
+	contextualHelp  map[IPanel]string // keyed by panel, paths change as tabs are added, removed or moved
+	workflows       map[string]IMovementNode
+	workflow        *activeWorkflow
+	focusHistory    focusHistory
+	started         bool // set once the initial focus was granted
 }
 
 var _ IPanel = &TopLevelListPanel{}
 
+type TopLevelListPanelOption func(*TopLevelListPanel)
+
+func NewTopLevelListPanel(listPanel *ListPanel, options ...TopLevelListPanelOption) *TopLevelListPanel {
+	listPanel.topLevel = true
+	topLevel := &TopLevelListPanel{
+		ListPanel: listPanel,
+	}
+	for _, option := range options {
+		option(topLevel)
+	}
+	return topLevel
+}
+
+// WithKeySequenceTimeout sets how long a partially typed key
+// sequence waits for its next key
+func WithKeySequenceTimeout(timeout time.Duration) TopLevelListPanelOption {
+	return func(m *TopLevelListPanel) {
+		m.keySequence.Timeout = timeout
+	}
+}
+
+// WithKeyMapConfig remaps key bindings by action ID at Init
+func WithKeyMapConfig(config KeyMapConfig) TopLevelListPanelOption {
+	return func(m *TopLevelListPanel) {
+		m.keyMapConfig = config
+	}
+}
+
+// WithKeyMapFile loads a keymap file at Init and remaps
+// key bindings by action ID
+func WithKeyMapFile(path string) TopLevelListPanelOption {
+	return func(m *TopLevelListPanel) {
+		m.keyMapFile = path
+	}
+}
+
+// WithKeyBindingConflictWarnings checks for conflicting key bindings
+// at Init and passes any conflicts found to report. If report is nil,
+// the conflicts are written to the debug output
+func WithKeyBindingConflictWarnings(report func([]KeyBindingConflict)) TopLevelListPanelOption {
+	return func(m *TopLevelListPanel) {
+		if report == nil {
+			report = func(conflicts []KeyBindingConflict) {
+				for _, conflict := range conflicts {
+					DebugPrintf("Key binding conflict: %v\n", conflict)
+				}
+			}
+		}
+		m.reportConflicts = report
+	}
+}
+
 func (m *TopLevelListPanel) Init(cmds chan tea.Cmd) {
 	m.ListPanel.SetPath([]int{})
 	m.cmds = cmds
 	m.ListPanel.Init(cmds)
+	m.applyKeyMap()
+	if m.reportConflicts != nil {
+		if conflicts := FindKeyBindingConflicts(m.ListPanel); len(conflicts) > 0 {
+			m.reportConflicts(conflicts)
+		}
+	}
+}
+
+func (m *TopLevelListPanel) applyKeyMap() {
+	config := m.keyMapConfig
+	if m.keyMapFile != "" {
+		config, m.keyMapErr = LoadKeyMapConfig(m.keyMapFile)
+		if m.keyMapErr != nil {
+			DebugPrintf("TopLevelListPanel: %v\n", m.keyMapErr)
+			return
+		}
+	}
+	if config == nil {
+		return
+	}
+	m.keyMapErr = applyKeyMapConfig(m.ListPanel, config, &m.globalKeyLayers)
+	if m.keyMapErr != nil {
+		DebugPrintf("TopLevelListPanel: %v\n", m.keyMapErr)
+	}
+}
+
+// KeyMapError returns the errors found while applying the keymap
+// given by WithKeyMapConfig or WithKeyMapFile, or nil
+func (m *TopLevelListPanel) KeyMapError() error {
+	return m.keyMapErr
 }
 
 func (m *TopLevelListPanel) FigureOutFocusGrant(msg FocusRequestMsg) *FocusGrantMsg {
@@ -30,25 +135,243 @@ func (m *TopLevelListPanel) FigureOutFocusGrant(msg FocusRequestMsg) *FocusGrant
 	case Self:
 		return &FocusGrantMsg{RoutePath: RoutePath{Path: msg.RequestedPath}, Relation: msg.Relation}
 	case Left, Right, Up, Down:
-		return nil
+		target := FindPanelInDirection(m.ListPanel, msg.RequestedPath, msg.Relation)
+		if target == nil {
+			return nil
+		}
+		return &FocusGrantMsg{RoutePath: RoutePath{Path: target.GetPath()}, Relation: msg.Relation}
+	case StartWorkflow, NextWorkflow, PrevWorkflow:
+		return m.moveInWorkflow(msg.Relation)
+	case FocusBack, FocusForward:
+		return m.moveInFocusHistory(msg.Relation)
 	default:
 		return nil
 	}
 }
 
+// GlobalShortcutOrder returns the order in which panels are offered
+// a key-stroke for their global key bindings. The first panel to use
+// the key wins:
+// 1. panels on the focus path, the most deeply nested first
+// 2. the remaining visible panels, depth first in path order
+// 3. panels in hidden tabs, depth first in path order
+func (m *TopLevelListPanel) GlobalShortcutOrder() []IPanel {
+	focusPath := FocusPath(m.ListPanel)
+	order := make([]IPanel, 0, len(focusPath))
+	onFocusPath := make(map[IPanel]bool)
+	for i := len(focusPath) - 1; i >= 0; i-- {
+		order = append(order, focusPath[i])
+		onFocusPath[focusPath[i]] = true
+	}
+	hidden := []IPanel{}
+	WalkPanels(m.ListPanel, func(panel IPanel) bool {
+		if onFocusPath[panel] {
+			return true
+		}
+		if panel.IsInHiddenTab() {
+			hidden = append(hidden, panel)
+		} else {
+			order = append(order, panel)
+		}
+		return true
+	})
+	return append(order, hidden...)
+}
+
+// ConsiderGlobalShortcuts offers the key-stroke to the global key
+// bindings of all panels and returns true if one of them used it
+func (m *TopLevelListPanel) ConsiderGlobalShortcuts(msg KeyMsg) bool {
+	for _, panel := range m.GlobalShortcutOrder() {
+		m.ListPanel.HandleMessage(ConsiderForGlobalShortcutMsg{
+			KeyMsg:    msg,
+			RoutePath: RoutePath{Path: panel.GetPath()},
+		})
+		if msg.IsUsed() {
+			DebugPrintf("TopLevelListPanel: global shortcut used by %v\n", panel.GetPath())
+			return true
+		}
+	}
+	return false
+}
+
+// PendingKeySequence returns the keys typed so far of
+// a partially typed key sequence
+func (m *TopLevelListPanel) PendingKeySequence() []KeyDef {
+	return m.keySequence.Pending
+}
+
+// CancelKeySequence drops the pending keys of a partially typed key sequence
+func (m *TopLevelListPanel) CancelKeySequence() {
+	if !m.keySequence.IsPending() {
+		return
+	}
+	m.keySequence.reset()
+	m.broadcastPendingKeySequence()
+}
+
+// PushGlobalKeyLayer pushes a key layer whose bindings are considered
+// before those of any panel, regardless of which panel is focused
+func (m *TopLevelListPanel) PushGlobalKeyLayer(layer *KeyLayer) {
+	m.globalKeyLayers.PushKeyLayer(layer)
+}
+
+func (m *TopLevelListPanel) PopGlobalKeyLayer() *KeyLayer {
+	return m.globalKeyLayers.PopKeyLayer()
+}
+
+// GlobalMode returns the name of the active global key layer
+func (m *TopLevelListPanel) GlobalMode() string {
+	return m.globalKeyLayers.Mode()
+}
+
+// SetContextValue sets an app-defined value that when clauses of
+// key bindings can refer to. A nil value removes the key
+func (m *TopLevelListPanel) SetContextValue(key string, value any) {
+	if m.contextValues == nil {
+		m.contextValues = make(map[string]any)
+	}
+	if value == nil {
+		delete(m.contextValues, key)
+		return
+	}
+	m.contextValues[key] = value
+}
+
+// KeyContext returns the current state of the UI that when clauses
+// of key bindings are evaluated against. The name of the active
+// workflow is available as "workflow"
+func (m *TopLevelListPanel) KeyContext() KeyContext {
+	ctx := KeyContext{
+		ActiveTabs: make(map[string]string),
+		Values:     make(map[string]any, len(m.contextValues)),
+	}
+	if m.workflow != nil {
+		ctx.Values["workflow"] = m.workflow.name
+	}
+	for key, value := range m.contextValues {
+		ctx.Values[key] = value
+	}
+	if focusPath := FocusPath(m.ListPanel); len(focusPath) > 0 {
+		focused := focusPath[len(focusPath)-1]
+		ctx.FocusedPanel = focused.GetName()
+		ctx.FocusedPath = focused.GetPath()
+	}
+	WalkPanels(m.ListPanel, func(panel IPanel) bool {
+		if listPanel, ok := panel.(*ListPanel); ok && listPanel.Layout.Orientation == ZStacked && listPanel.Name != "" && len(listPanel.Panels) > 0 {
+			ctx.ActiveTabs[listPanel.Name] = listPanel.GetSelected().GetName()
+		}
+		return true
+	})
+	return ctx
+}
+
+// HandleKeyMsg dispatches a key-stroke, first to the global key layers,
+// then to global key bindings and then along the focus path, while
+// keeping track of key sequences
+func (m *TopLevelListPanel) HandleKeyMsg(msg KeyMsg) {
+	if o := m.modalOverlay(); o != nil {
+		o.panel.HandleMessage(msg)
+		return
+	}
+
+	now := time.Now()
+	if m.keySequence.isExpired(now) {
+		m.CancelKeySequence()
+	}
+	if m.keySequence.IsPending() && isEscapeKey(msg.EventKey) {
+		m.CancelKeySequence()
+		return
+	}
+
+	ctx := m.KeyContext()
+	msg.Context = &ctx
+	msg.Sequence = &m.keySequence
+	m.keySequence.beginKey()
+	m.cmds <- KeyBindingsHandler(m.globalKeyLayers.ActiveKeyBindings(nil), msg, false)
+	if !msg.IsUsed() && !m.ConsiderGlobalShortcuts(msg) {
+		m.ListPanel.HandleMessage(msg)
+	}
+
+	if m.keySequence.finishKey(KeyDefFromEventKey(msg.EventKey), now) {
+		DebugPrintf("TopLevelListPanel: pending key sequence %v\n", RenderKeySequence(m.keySequence.Pending))
+		m.broadcastPendingKeySequence()
+		if m.keySequence.IsPending() {
+			m.cmds <- m.keySequence.timeoutCmd()
+		}
+	}
+}
+
+// grantFocus revokes the current focus and sends focusGrantMsg,
+// if there is one
+func (m *TopLevelListPanel) grantFocus(focusGrantMsg *FocusGrantMsg) {
+	if focusGrantMsg == nil {
+		return
+	}
+	m.ListPanel.HandleMessage(FocusRevokeMsg{})
+	newCmd := func() tea.Msg {
+		return *focusGrantMsg
+	}
+	m.cmds <- newCmd
+}
+
 func (m *TopLevelListPanel) HandleMessage(msg Msg) {
 	DebugPrintf("TopLevelListPanel received message: %T %+v\n", msg, msg)
+	if statusBarAffectedBy(msg) {
+		defer m.updateStatusBar()
+	}
+	if _, ok := msg.(ResizeMsg); ok {
+		defer m.grantInitialFocus()
+	}
+	if m.handleOverlayMsg(msg) {
+		return
+	}
 	switch msg := msg.(type) {
-	case FocusRequestMsg:
-		m.ListPanel.HandleMessage(FocusRevokeMsg{})
-		focusGrantMsg := m.FigureOutFocusGrant(msg)
-		if focusGrantMsg != nil {
-			newCmd := func() tea.Msg {
-				return *focusGrantMsg
+	case KeyMsg:
+		m.HandleKeyMsg(msg)
+
+	case PushGlobalKeyLayerMsg:
+		m.PushGlobalKeyLayer(msg.Layer)
+
+	case PopGlobalKeyLayerMsg:
+		m.PopGlobalKeyLayer()
+
+	case SetContextValueMsg:
+		m.SetContextValue(msg.Key, msg.Value)
+
+	case ContextualHelpTextMsg:
+		if panel := FindPanelByPath(m.ListPanel, msg.Path); panel != nil {
+			if m.contextualHelp == nil {
+				m.contextualHelp = make(map[IPanel]string)
 			}
-			m.cmds <- newCmd
+			m.contextualHelp[panel] = msg.Text
 		}
 
+	case TabRemovedMsg:
+		m.ListPanel.HandleMessage(msg)
+		for panel := range m.contextualHelp {
+			if FindPanelByPath(m.ListPanel, panel.GetPath()) != panel {
+				delete(m.contextualHelp, panel)
+			}
+		}
+
+	case StartWorkflowMsg:
+		m.grantFocus(m.StartWorkflow(msg.Name))
+
+	case AbandonWorkflowMsg:
+		m.AbandonWorkflow()
+
+	case keySequenceTimeoutMsg:
+		if msg.generation == m.keySequence.generation {
+			m.CancelKeySequence()
+		}
+
+	case FocusRequestMsg:
+		m.grantFocus(m.FigureOutFocusGrant(msg))
+
+	case FocusGrantMsg:
+		m.ListPanel.HandleMessage(msg)
+		m.recordFocus(msg.Relation)
+
 	default:
 		m.ListPanel.HandleMessage(msg)
 	}
@@ -0,0 +1,290 @@
+package peanutbutter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// KeyContext describes the state of the UI that key bindings
+// can be conditioned on with a when clause
+type KeyContext struct {
+	FocusedPanel string            // name of the most deeply nested focused panel
+	FocusedPath  []int             // path of the most deeply nested focused panel
+	ActiveTabs   map[string]string // name of each named ZStacked ListPanel -> name of its selected tab
+	Values       map[string]any    // app-defined context keys
+}
+
+// Lookup resolves an identifier of a when expression:
+// "focused" is the name of the focused panel, "tab.<name>" is the
+// selected tab of the ZStacked ListPanel called <name>, and anything
+// else is looked up in the app-defined values
+func (ctx KeyContext) Lookup(name string) any {
+	if name == "focused" {
+		return ctx.FocusedPanel
+	}
+	if tabs, ok := strings.CutPrefix(name, "tab."); ok {
+		if tab, ok := ctx.ActiveTabs[tabs]; ok {
+			return tab
+		}
+		return nil
+	}
+	return ctx.Values[name]
+}
+
+// WhenExpr is a compiled when expression, e.g.
+//
+//	focused == "editor" && !readOnly
+//	tab.main == 'logs' || (debug && focused != "console")
+//
+// It supports string, number and boolean literals, identifiers
+// resolved by KeyContext.Lookup, ==, !=, &&, ||, ! and parentheses.
+// A value is true if it is true, a non-empty string or a non-zero number
+type WhenExpr struct {
+	Source string
+	eval   func(ctx KeyContext) any
+}
+
+func (e *WhenExpr) Eval(ctx KeyContext) bool {
+	return isTruthy(e.eval(ctx))
+}
+
+func (e *WhenExpr) String() string {
+	return e.Source
+}
+
+func isTruthy(value any) bool {
+	switch value := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return value
+	case string:
+		return value != ""
+	case float64:
+		return value != 0
+	case int:
+		return value != 0
+	}
+	return true
+}
+
+func whenValuesEqual(a any, b any) bool {
+	if a == nil {
+		a = ""
+	}
+	if b == nil {
+		b = ""
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+type whenToken struct {
+	kind  string // "ident", "string", "number", "op", "eof"
+	text  string
+	value any
+	pos   int
+}
+
+func tokenizeWhenExpr(src string) ([]whenToken, error) {
+	tokens := []whenToken{}
+	runes := []rune(src)
+	i := 0
+	for i < len(runes) {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			tokens = append(tokens, whenToken{kind: "op", text: string(r), pos: i})
+			i++
+		case r == '!' || r == '=' || r == '&' || r == '|':
+			if i+1 < len(runes) {
+				op := string(runes[i : i+2])
+				if op == "==" || op == "!=" || op == "&&" || op == "||" {
+					tokens = append(tokens, whenToken{kind: "op", text: op, pos: i})
+					i += 2
+					continue
+				}
+			}
+			if r != '!' {
+				return nil, fmt.Errorf("when %q: unexpected %q at %d", src, string(r), i)
+			}
+			tokens = append(tokens, whenToken{kind: "op", text: "!", pos: i})
+			i++
+		case r == '"' || r == '\'':
+			start := i
+			var sb strings.Builder
+			i++
+			for i < len(runes) && runes[i] != r {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("when %q: unterminated string at %d", src, start)
+			}
+			i++
+			tokens = append(tokens, whenToken{kind: "string", value: sb.String(), pos: start})
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			number, err := strconv.ParseFloat(string(runes[start:i]), 64)
+			if err != nil {
+				return nil, fmt.Errorf("when %q: invalid number at %d", src, start)
+			}
+			tokens = append(tokens, whenToken{kind: "number", value: number, pos: start})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || strings.ContainsRune("_.-", runes[i])) {
+				i++
+			}
+			tokens = append(tokens, whenToken{kind: "ident", text: string(runes[start:i]), pos: start})
+		default:
+			return nil, fmt.Errorf("when %q: unexpected %q at %d", src, string(r), i)
+		}
+	}
+	return append(tokens, whenToken{kind: "eof", pos: len(runes)}), nil
+}
+
+type whenParser struct {
+	src    string
+	tokens []whenToken
+	pos    int
+}
+
+func (p *whenParser) peek() whenToken {
+	return p.tokens[p.pos]
+}
+
+func (p *whenParser) isOp(op string) bool {
+	t := p.peek()
+	return t.kind == "op" && t.text == op
+}
+
+func (p *whenParser) errorf(format string, a ...any) error {
+	return fmt.Errorf("when %q: %s at %d", p.src, fmt.Sprintf(format, a...), p.peek().pos)
+}
+
+func (p *whenParser) parseOr() (func(KeyContext) any, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("||") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(ctx KeyContext) any { return isTruthy(l(ctx)) || isTruthy(right(ctx)) }
+	}
+	return left, nil
+}
+
+func (p *whenParser) parseAnd() (func(KeyContext) any, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("&&") {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(ctx KeyContext) any { return isTruthy(l(ctx)) && isTruthy(right(ctx)) }
+	}
+	return left, nil
+}
+
+func (p *whenParser) parseUnary() (func(KeyContext) any, error) {
+	if p.isOp("!") {
+		p.pos++
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx KeyContext) any { return !isTruthy(operand(ctx)) }, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *whenParser) parseComparison() (func(KeyContext) any, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if p.isOp("==") || p.isOp("!=") {
+		negate := p.isOp("!=")
+		p.pos++
+		right, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx KeyContext) any {
+			return whenValuesEqual(left(ctx), right(ctx)) != negate
+		}, nil
+	}
+	return left, nil
+}
+
+func (p *whenParser) parsePrimary() (func(KeyContext) any, error) {
+	t := p.peek()
+	switch t.kind {
+	case "string", "number":
+		p.pos++
+		value := t.value
+		return func(KeyContext) any { return value }, nil
+	case "ident":
+		p.pos++
+		switch t.text {
+		case "true":
+			return func(KeyContext) any { return true }, nil
+		case "false":
+			return func(KeyContext) any { return false }, nil
+		}
+		name := t.text
+		return func(ctx KeyContext) any { return ctx.Lookup(name) }, nil
+	case "op":
+		if t.text == "(" {
+			p.pos++
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if !p.isOp(")") {
+				return nil, p.errorf("expected )")
+			}
+			p.pos++
+			return inner, nil
+		}
+	case "eof":
+		return nil, p.errorf("unexpected end of expression")
+	}
+	return nil, p.errorf("unexpected %q", t.text)
+}
+
+// ParseWhenExpr compiles a when expression
+func ParseWhenExpr(src string) (*WhenExpr, error) {
+	tokens, err := tokenizeWhenExpr(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &whenParser{src: src, tokens: tokens}
+	eval, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != "eof" {
+		return nil, p.errorf("unexpected %q", p.peek().text)
+	}
+	return &WhenExpr{Source: src, eval: eval}, nil
+}
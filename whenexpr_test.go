@@ -0,0 +1,93 @@
+package peanutbutter
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestWhenExprEval(t *testing.T) {
+	ctx := KeyContext{
+		FocusedPanel: "a",
+		ActiveTabs:   map[string]string{"tabs": "c"},
+		Values:       map[string]any{"readonly": true, "count": 3},
+	}
+	tests := map[string]bool{
+		`focused == "a"`:                  true,
+		`focused != 'a'`:                  false,
+		`tab.tabs == "c" && readonly`:     true,
+		`!readonly || count == 3`:         true,
+		`(count == 4 || missing) && true`: false,
+		`missing == ""`:                   true,
+		`tab.nope`:                        false,
+	}
+	for src, want := range tests {
+		expr, err := ParseWhenExpr(src)
+		if err != nil {
+			t.Errorf("%s: %v", src, err)
+			continue
+		}
+		if got := expr.Eval(ctx); got != want {
+			t.Errorf("%s: got %v, want %v", src, got, want)
+		}
+	}
+}
+
+func TestParseWhenExprErrors(t *testing.T) {
+	for _, src := range []string{`a ==`, `(a`, `a = b`, `"x`, `a b`} {
+		if _, err := ParseWhenExpr(src); err == nil {
+			t.Errorf("%s: expected an error", src)
+		}
+	}
+}
+
+func TestWhenClauseGatesKeyBinding(t *testing.T) {
+	l := newTestLayout(t)
+	fired := 0
+	keyBinding := testKeyBinding("x", func() tea.Cmd {
+		fired++
+		return nil
+	}, WithWhenExpr(`focused == "a" && tab.tabs == "d"`))
+	keyBinding.Override = true
+	l.a.AddKeyBinding(keyBinding)
+	l.focus(l.a)
+
+	l.press(tcell.KeyRune, 'x', 0)
+	l.top.cmds <- l.tabs.SetSelected(1)
+	l.pump()
+	l.press(tcell.KeyRune, 'x', 0)
+	if fired != 1 {
+		t.Errorf("fired %d times, want once with tab d selected", fired)
+	}
+}
+
+func TestContextValueMsg(t *testing.T) {
+	l := newTestLayout(t)
+	l.top.HandleMessage(SetContextValueMsg{Key: "dirty", Value: true})
+	if ctx := l.top.KeyContext(); ctx.Lookup("dirty") != true {
+		t.Errorf("dirty is %v", ctx.Lookup("dirty"))
+	}
+	l.top.HandleMessage(SetContextValueMsg{Key: "dirty"})
+	if ctx := l.top.KeyContext(); ctx.Lookup("dirty") != nil {
+		t.Errorf("dirty is %v after removing it", ctx.Lookup("dirty"))
+	}
+}
+
+func TestShortHelpTextsFollowWhenClauses(t *testing.T) {
+	keyBindings := []*KeyBinding{
+		testKeyBinding("s", nil, WithShortHelp("save"), WithWhenExpr("dirty")),
+		testKeyBinding("q", nil, WithShortHelp("quit")),
+	}
+	if got := ShortHelpTexts(keyBindings); len(got) != 2 {
+		t.Errorf("without a context: got %q", got)
+	}
+	clean := KeyContext{Values: map[string]any{"dirty": false}}
+	if got := ShortHelpTextsInContext(keyBindings, &clean); len(got) != 1 || got[0] != "q: quit" {
+		t.Errorf("clean: got %q", got)
+	}
+	dirty := KeyContext{Values: map[string]any{"dirty": true}}
+	if got := ShortHelpTextsInContext(keyBindings, &dirty); len(got) != 2 {
+		t.Errorf("dirty: got %q", got)
+	}
+}
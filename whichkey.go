@@ -0,0 +1,184 @@
+package peanutbutter
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// KeyContinuation is a key binding that a pending key sequence
+// can still complete, along with the keys left to type
+type KeyContinuation struct {
+	Keys    []KeyDef
+	Binding *KeyBinding
+}
+
+// KeySequenceContinuations returns, for every key sequence of the
+// bindings that starts with pending, the keys that complete it
+func KeySequenceContinuations(keyBindings []*KeyBinding, pending []KeyDef) []KeyContinuation {
+	continuations := []KeyContinuation{}
+	for _, keyBinding := range keyBindings {
+		for _, sequence := range keyBinding.KeySequences {
+			if len(sequence) <= len(pending) {
+				continue
+			}
+			matches := true
+			for i := 0; matches && i < len(pending); i++ {
+				matches = sequence[i].matchesKeyDef(pending[i])
+			}
+			if matches {
+				continuations = append(continuations, KeyContinuation{Keys: sequence[len(pending):], Binding: keyBinding})
+			}
+		}
+	}
+	return continuations
+}
+
+// WhichKeyContinuations returns the continuations of the pending
+// key sequence among the bindings a key-stroke can currently reach
+func (m *TopLevelListPanel) WhichKeyContinuations() []KeyContinuation {
+	ctx := m.KeyContext()
+	keyBindings := []*KeyBinding{}
+	for _, entry := range m.reachableKeyBindings(&ctx) {
+		keyBindings = append(keyBindings, entry.Binding)
+	}
+	return KeySequenceContinuations(keyBindings, m.keySequence.Pending)
+}
+
+// WithWhichKey shows a popup listing the continuations of a pending
+// key sequence next to the focused panel, like the which-key plugin
+// of neovim. The popup goes away once the sequence completes or is cancelled
+func WithWhichKey(enabled bool) TopLevelListPanelOption {
+	return func(m *TopLevelListPanel) {
+		m.whichKeyEnabled = enabled
+	}
+}
+
+// WhichKeyPlacement places an overlay of the given size
+// at the bottom right corner of the focused panel
+func WhichKeyPlacement(width int, height int) OverlayPlacement {
+	return func(screen Rect, focused Rect) Rect {
+		return Rect{
+			X:      focused.X + focused.Width - width,
+			Y:      focused.Y + focused.Height - height,
+			Width:  width,
+			Height: height,
+		}
+	}
+}
+
+// broadcastPendingKeySequence lets all panels know the pending
+// key sequence changed and updates the which-key popup
+func (m *TopLevelListPanel) broadcastPendingKeySequence() {
+	pending := append([]KeyDef{}, m.keySequence.Pending...)
+	m.ListPanel.HandleMessage(KeySequencePendingMsg{Pending: pending})
+	m.updateWhichKey()
+}
+
+func (m *TopLevelListPanel) updateWhichKey() {
+	if m.whichKey != nil {
+		m.HideOverlay(m.whichKey)
+		m.whichKey = nil
+	}
+	if !m.whichKeyEnabled || !m.keySequence.IsPending() {
+		return
+	}
+	continuations := m.WhichKeyContinuations()
+	if len(continuations) == 0 {
+		return
+	}
+	whichKey := NewWhichKey(continuations)
+	m.whichKey = NewShortCutPanel(whichKey, WithTitle(RenderKeySequence(m.keySequence.Pending)), WithName("WhichKey"))
+	width, height := whichKey.Size()
+	m.ShowOverlay(m.whichKey, WhichKeyPlacement(width+2, height+2), false)
+}
+
+// WhichKey is a leaf model listing key continuations and their ShortHelp
+type WhichKey struct {
+	continuations []KeyContinuation
+	width         int
+	height        int
+	redraw        bool
+	Style         WhichKeyStyle
+}
+
+type WhichKeyStyle struct {
+	Keys lipgloss.Style
+	Help lipgloss.Style
+}
+
+var DefaultWhichKeyStyle = WhichKeyStyle{
+	Keys: Fg(Plt.Mauve()).Bold(true),
+	Help: lipgloss.NewStyle(),
+}
+
+var _ ILeafModel = &WhichKey{}
+var _ ILeafModelWithView = &WhichKey{}
+
+func NewWhichKey(continuations []KeyContinuation) *WhichKey {
+	return &WhichKey{
+		continuations: continuations,
+		redraw:        true,
+		Style:         DefaultWhichKeyStyle,
+	}
+}
+
+func (w *WhichKey) keysWidth() int {
+	width := 0
+	for _, continuation := range w.continuations {
+		width = max(width, runewidth.StringWidth(RenderKeySequence(continuation.Keys)))
+	}
+	return width
+}
+
+// Size returns the size needed to show all continuations
+func (w *WhichKey) Size() (int, int) {
+	width := 0
+	for _, continuation := range w.continuations {
+		width = max(width, runewidth.StringWidth(continuation.Binding.ShortHelp))
+	}
+	return w.keysWidth() + 2 + width, len(w.continuations)
+}
+
+func (w *WhichKey) Init() tea.Cmd {
+	return nil
+}
+
+func (w *WhichKey) Update(msg Msg) tea.Cmd {
+	if msg, ok := msg.(ResizeMsg); ok {
+		w.width = msg.Width
+		w.height = msg.Height
+		w.redraw = true
+	}
+	return nil
+}
+
+func (w *WhichKey) NeedsRedraw() bool {
+	return w.redraw
+}
+
+func (w *WhichKey) View() string {
+	w.redraw = false
+	if w.width <= 0 || w.height <= 0 {
+		return ""
+	}
+	keysWidth := min(w.keysWidth(), w.width)
+	lines := []string{}
+	for _, continuation := range w.continuations {
+		if len(lines) == w.height {
+			break
+		}
+		keys := w.Style.Keys.Render(padLine(RenderKeySequence(continuation.Keys), keysWidth))
+		help := ""
+		if helpWidth := w.width - keysWidth - 2; helpWidth > 0 {
+			help = "  " + w.Style.Help.Render(padLine(continuation.Binding.ShortHelp, helpWidth))
+		}
+		lines = append(lines, keys+help)
+	}
+	for len(lines) < w.height {
+		lines = append(lines, strings.Repeat(" ", w.width))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,67 @@
+package peanutbutter
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestKeySequenceContinuations(t *testing.T) {
+	keyBindings := []*KeyBinding{
+		testKeyBinding("Ctrl+W h", noop, WithShortHelp("left")),
+		testKeyBinding("Ctrl+W l", noop, WithShortHelp("right")),
+		testKeyBinding("g g", noop, WithShortHelp("top")),
+		testKeyBinding("Ctrl+W", noop),
+	}
+	pending, err := ParseKeySequence("Ctrl+W")
+	if err != nil {
+		t.Fatal(err)
+	}
+	continuations := KeySequenceContinuations(keyBindings, pending)
+	if len(continuations) != 2 {
+		t.Fatalf("%d continuations, want 2", len(continuations))
+	}
+	for i, want := range []string{"h", "l"} {
+		if keys := RenderKeySequence(continuations[i].Keys); keys != want {
+			t.Errorf("continuation %d is %q, want %q", i, keys, want)
+		}
+	}
+}
+
+func TestWhichKeyPopup(t *testing.T) {
+	l := newTestLayout(t, WithWhichKey(true))
+	for _, keys := range []string{"Ctrl+W h", "Ctrl+W l"} {
+		keyBinding := testKeyBinding(keys, noop, WithShortHelp(keys))
+		keyBinding.Override = true
+		l.a.AddKeyBinding(keyBinding)
+	}
+	l.focus(l.a)
+
+	// keys are sent without running the commands, which would
+	// wait for the key sequence timeout
+	l.top.HandleMessage(testKeyMsg(tcell.KeyCtrlW, 0, tcell.ModCtrl))
+	if l.top.whichKey == nil || !l.top.IsOverlayShown(l.top.whichKey) {
+		t.Fatal("popup not shown while a sequence is pending")
+	}
+	whichKey := l.top.whichKey.(*ShortCutPanel).Model.(*WhichKey)
+	if len(whichKey.continuations) != 2 {
+		t.Errorf("popup lists %d continuations, want 2", len(whichKey.continuations))
+	}
+	popup := l.top.whichKey
+	l.top.HandleMessage(testKeyMsg(tcell.KeyRune, 'h', 0))
+	if l.top.whichKey != nil || l.top.IsOverlayShown(popup) {
+		t.Error("popup still shown after the sequence completed")
+	}
+}
+
+func TestWhichKeyDisabled(t *testing.T) {
+	l := newTestLayout(t)
+	keyBinding := testKeyBinding("Ctrl+W h", noop)
+	keyBinding.Override = true
+	l.a.AddKeyBinding(keyBinding)
+	l.focus(l.a)
+	l.top.HandleMessage(testKeyMsg(tcell.KeyCtrlW, 0, tcell.ModCtrl))
+	if l.top.whichKey != nil {
+		t.Error("popup shown without WithWhichKey")
+	}
+}
@@ -0,0 +1,155 @@
+package peanutbutter
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// StartWorkflowMsg asks the top level panel to start the workflow
+// registered as Name and focus its first panel
+type StartWorkflowMsg struct {
+	Name string
+}
+
+// AbandonWorkflowMsg asks the top level panel to stop
+// tracking the active workflow. The focus stays where it is
+type AbandonWorkflowMsg struct{}
+
+// WorkflowChangedMsg is broadcast when a workflow starts, moves to
+// another panel, is finished or is abandoned. Name is empty once
+// no workflow is active
+type WorkflowChangedMsg struct {
+	Name     string
+	Path     []int // path of the current panel of the workflow
+	Finished bool  // set when the workflow was left by moving past its last panel
+}
+
+// To move through the active workflow, send a FocusRequestMsg with
+// the Relation NextWorkflow or PrevWorkflow. StartWorkflow restarts
+// the active workflow from its first panel
+
+type activeWorkflow struct {
+	name    string
+	root    IMovementNode
+	current IPanel
+}
+
+// RegisterWorkflow registers a named workflow, a tree of PanelSequences
+// and SelectorNodes describing the order in which its panels are visited
+func (m *TopLevelListPanel) RegisterWorkflow(name string, root IMovementNode) {
+	if m.workflows == nil {
+		m.workflows = make(map[string]IMovementNode)
+	}
+	m.workflows[name] = root
+}
+
+func WithWorkflow(name string, root IMovementNode) TopLevelListPanelOption {
+	return func(m *TopLevelListPanel) {
+		m.RegisterWorkflow(name, root)
+	}
+}
+
+// WithWorkflowKeyBindings adds global key bindings that move to the
+// next and previous panel of the active workflow. They only apply
+// while a workflow is active
+func WithWorkflowKeyBindings(next KeyBinding, prev KeyBinding) TopLevelListPanelOption {
+	nextKb, prevKb := next, prev
+	return func(m *TopLevelListPanel) {
+		for _, kb := range []*KeyBinding{&nextKb, &prevKb} {
+			relation := NextWorkflow
+			if kb == &prevKb {
+				relation = PrevWorkflow
+			}
+			kb.Global = true
+			kb.When = func(KeyContext) bool {
+				return m.workflow != nil
+			}
+			kb.Func = func() tea.Cmd {
+				// moveInWorkflow finds the active workflow when the
+				// request is handled, so nothing is read here
+				return func() tea.Msg {
+					return FocusRequestMsg{Relation: relation}
+				}
+			}
+			m.ListPanel.AddKeyBinding(kb)
+		}
+	}
+}
+
+// ActiveWorkflow returns the name of the active workflow and its
+// current panel, or "" and nil if no workflow is active
+func (m *TopLevelListPanel) ActiveWorkflow() (string, IPanel) {
+	if m.workflow == nil {
+		return "", nil
+	}
+	return m.workflow.name, m.workflow.current
+}
+
+func (m *TopLevelListPanel) workflowChangedCmd(finished bool) tea.Cmd {
+	changedMsg := WorkflowChangedMsg{Finished: finished}
+	if m.workflow != nil {
+		changedMsg.Name = m.workflow.name
+		changedMsg.Path = m.workflow.current.GetPath()
+	}
+	return func() tea.Msg {
+		return changedMsg
+	}
+}
+
+// StartWorkflow makes the named workflow the active one and
+// returns the focus grant for its first panel
+func (m *TopLevelListPanel) StartWorkflow(name string) *FocusGrantMsg {
+	root, ok := m.workflows[name]
+	if !ok {
+		DebugPrintf("TopLevelListPanel: unknown workflow %q\n", name)
+		return nil
+	}
+	first := root.First()
+	if first == nil {
+		return nil
+	}
+	m.workflow = &activeWorkflow{name: name, root: root, current: first}
+	m.cmds <- m.workflowChangedCmd(false)
+	return &FocusGrantMsg{RoutePath: RoutePath{Path: first.GetPath()}, Relation: StartWorkflow}
+}
+
+func (m *TopLevelListPanel) AbandonWorkflow() {
+	if m.workflow == nil {
+		return
+	}
+	m.workflow = nil
+	m.cmds <- m.workflowChangedCmd(false)
+}
+
+// moveInWorkflow returns the focus grant for the next or previous
+// panel of the active workflow. The position is taken from the focused
+// panel if it belongs to the workflow. Moving past the last panel
+// finishes the workflow; moving back from the first one does nothing
+func (m *TopLevelListPanel) moveInWorkflow(relation Relation) *FocusGrantMsg {
+	if m.workflow == nil {
+		return nil
+	}
+	if relation == StartWorkflow {
+		return m.StartWorkflow(m.workflow.name)
+	}
+	if focusPath := FocusPath(m.ListPanel); len(focusPath) > 0 {
+		if focused := focusPath[len(focusPath)-1]; m.workflow.root.contains(focused) {
+			m.workflow.current = focused
+		}
+	}
+	var target IPanel
+	if relation == NextWorkflow {
+		target = m.workflow.root.Next(m.workflow.current)
+	} else {
+		target = m.workflow.root.Previous(m.workflow.current)
+	}
+	if target == nil {
+		if relation == NextWorkflow {
+			m.workflow = nil
+			m.cmds <- m.workflowChangedCmd(true)
+		}
+		return nil
+	}
+	m.workflow.current = target
+	m.cmds <- m.workflowChangedCmd(false)
+	return &FocusGrantMsg{RoutePath: RoutePath{Path: target.GetPath()}, Relation: relation}
+}
@@ -0,0 +1,105 @@
+package peanutbutter
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gdamore/tcell/v2"
+)
+
+func newWorkflowLayout(t *testing.T) *testLayout {
+	t.Helper()
+	l := newTestLayout(t, WithWorkflowKeyBindings(
+		*NewKeyBinding(WithKeys("Ctrl+N"), WithEnabled(true)),
+		*NewKeyBinding(WithKeys("Ctrl+P"), WithEnabled(true)),
+	))
+	l.top.RegisterWorkflow("setup", NewPanelSequence(l.a, l.b, l.c))
+	return l
+}
+
+func workflowChangedMsgs(msgs []tea.Msg) []WorkflowChangedMsg {
+	changed := []WorkflowChangedMsg{}
+	for _, msg := range msgs {
+		if changedMsg, ok := msg.(WorkflowChangedMsg); ok {
+			changed = append(changed, changedMsg)
+		}
+	}
+	return changed
+}
+
+func TestWorkflowRunsThrough(t *testing.T) {
+	l := newWorkflowLayout(t)
+	l.top.HandleMessage(StartWorkflowMsg{Name: "setup"})
+	msgs := l.pump()
+	if name, current := l.top.ActiveWorkflow(); name != "setup" || current != l.a || l.focused() != l.a {
+		t.Fatalf("workflow %q at %v, focus on %v", name, current, l.focused())
+	}
+	if changed := workflowChangedMsgs(msgs); len(changed) != 1 || changed[0].Name != "setup" {
+		t.Errorf("broadcast %+v", changed)
+	}
+	if value := l.top.KeyContext().Values["workflow"]; value != "setup" {
+		t.Errorf("key context has workflow %v", value)
+	}
+
+	l.press(tcell.KeyCtrlN, 0, tcell.ModCtrl)
+	l.press(tcell.KeyCtrlN, 0, tcell.ModCtrl)
+	if l.focused() != l.c {
+		t.Fatalf("focus on %v, want c", l.focused().GetName())
+	}
+	l.press(tcell.KeyCtrlP, 0, tcell.ModCtrl)
+	if l.focused() != l.b {
+		t.Fatalf("focus on %v after going back, want b", l.focused().GetName())
+	}
+	if keyMsgs := l.leaf(l.b).keyMsgs(); len(keyMsgs) != 0 {
+		t.Errorf("b got %d workflow keys", len(keyMsgs))
+	}
+
+	l.press(tcell.KeyCtrlN, 0, tcell.ModCtrl)
+	l.top.HandleMessage(testKeyMsg(tcell.KeyCtrlN, 0, tcell.ModCtrl))
+	changed := workflowChangedMsgs(l.pump())
+	if len(changed) != 1 || !changed[0].Finished || changed[0].Name != "" {
+		t.Errorf("broadcast %+v when moving past the last panel", changed)
+	}
+	if name, _ := l.top.ActiveWorkflow(); name != "" || l.focused() != l.c {
+		t.Errorf("workflow %q still active, focus on %v", name, l.focused().GetName())
+	}
+}
+
+func TestWorkflowKeysNeedActiveWorkflow(t *testing.T) {
+	l := newWorkflowLayout(t)
+	l.focus(l.a)
+	l.press(tcell.KeyCtrlN, 0, tcell.ModCtrl)
+	if l.focused() != l.a || len(l.leaf(l.a).keyMsgs()) != 1 {
+		t.Errorf("focus on %v, a got %d keys", l.focused().GetName(), len(l.leaf(l.a).keyMsgs()))
+	}
+}
+
+func TestWorkflowFollowsFocus(t *testing.T) {
+	l := newWorkflowLayout(t)
+	l.top.HandleMessage(StartWorkflowMsg{Name: "setup"})
+	l.pump()
+	l.focus(l.b)
+	l.press(tcell.KeyCtrlN, 0, tcell.ModCtrl)
+	if l.focused() != l.c {
+		t.Errorf("focus on %v, want c", l.focused().GetName())
+	}
+}
+
+func TestAbandonWorkflow(t *testing.T) {
+	l := newWorkflowLayout(t)
+	l.top.HandleMessage(StartWorkflowMsg{Name: "setup"})
+	l.pump()
+	l.top.HandleMessage(AbandonWorkflowMsg{})
+	changed := workflowChangedMsgs(l.pump())
+	if len(changed) != 1 || changed[0].Name != "" || changed[0].Finished {
+		t.Errorf("broadcast %+v", changed)
+	}
+	if name, _ := l.top.ActiveWorkflow(); name != "" || l.focused() != l.a {
+		t.Errorf("workflow %q active, focus on %v", name, l.focused().GetName())
+	}
+
+	l.top.HandleMessage(StartWorkflowMsg{Name: "unknown"})
+	if name, _ := l.top.ActiveWorkflow(); name != "" {
+		t.Errorf("unknown workflow started as %q", name)
+	}
+}